@@ -3,55 +3,55 @@
 @test "Pass when parsing a valid Kubernetes config YAML file" {
   run bin/kubeval fixtures/valid.yaml
   [ "$status" -eq 0 ]
-  [ "$output" = "PASS - fixtures/valid.yaml contains a valid ReplicationController (bob)" ]
+  [ "${lines[0]}" = "PASS - fixtures/valid.yaml contains a valid ReplicationController (bob)" ]
 }
 
 @test "Pass when parsing a valid Kubernetes config YAML file on stdin" {
   run bash -c "cat fixtures/valid.yaml | bin/kubeval"
   [ "$status" -eq 0 ]
-  [ "$output" = "PASS - stdin contains a valid ReplicationController (bob)" ]
+  [ "${lines[0]}" = "PASS - stdin contains a valid ReplicationController (bob)" ]
 }
 
 @test "Pass when parsing a valid Kubernetes config YAML file explicitly on stdin" {
   run bash -c "cat fixtures/valid.yaml | bin/kubeval -"
   [ "$status" -eq 0 ]
-  [ "$output" = "PASS - stdin contains a valid ReplicationController (bob)" ]
+  [ "${lines[0]}" = "PASS - stdin contains a valid ReplicationController (bob)" ]
 }
 
 @test "Pass when parsing a valid Kubernetes config JSON file" {
   run bin/kubeval fixtures/valid.json
   [ "$status" -eq 0 ]
-  [ "$output" = "PASS - fixtures/valid.json contains a valid Deployment (default.nginx-deployment)" ]
+  [ "${lines[0]}" = "PASS - fixtures/valid.json contains a valid Deployment (default.nginx-deployment)" ]
 }
 
 @test "Pass when parsing a Kubernetes file with string and integer quantities" {
   run bin/kubeval fixtures/quantity.yaml
   [ "$status" -eq 0 ]
-  [ "$output" = "PASS - fixtures/quantity.yaml contains a valid LimitRange (mem-limit-range)" ]
+  [ "${lines[0]}" = "PASS - fixtures/quantity.yaml contains a valid LimitRange (mem-limit-range)" ]
 }
 
 @test "Pass when parsing a valid Kubernetes config file with int_to_string vars" {
   run bin/kubeval fixtures/int_or_string.yaml
   [ "$status" -eq 0 ]
-  [ "$output" = "PASS - fixtures/int_or_string.yaml contains a valid Service (kube-system.heapster)" ]
+  [ "${lines[0]}" = "PASS - fixtures/int_or_string.yaml contains a valid Service (kube-system.heapster)" ]
 }
 
 @test "Pass when parsing a valid Kubernetes config file with null arrays" {
   run bin/kubeval fixtures/null_array.yaml
   [ "$status" -eq 0 ]
-  [ "$output" = "PASS - fixtures/null_array.yaml contains a valid Deployment (kube-system.kubernetes-dashboard)" ]
+  [ "${lines[0]}" = "PASS - fixtures/null_array.yaml contains a valid Deployment (kube-system.kubernetes-dashboard)" ]
 }
 
 @test "Pass when parsing a valid Kubernetes config file with null strings" {
   run bin/kubeval fixtures/null_string.yaml
   [ "$status" -eq 0 ]
-  [ "$output" = "PASS - fixtures/null_string.yaml contains a valid Service (frontend)" ]
+  [ "${lines[0]}" = "PASS - fixtures/null_string.yaml contains a valid Service (frontend)" ]
 }
 
 @test "Pass when parsing a valid Kubernetes config YAML file with generate name" {
   run bin/kubeval fixtures/generate_name.yaml
   [ "$status" -eq 0 ]
-  [ "$output" = "PASS - fixtures/generate_name.yaml contains a valid Job (pi-{{ generateName }})" ]
+  [ "${lines[0]}" = "PASS - fixtures/generate_name.yaml contains a valid Job (pi-{{ generateName }})" ]
 }
 
 @test "Pass when parsing a multi-document config file" {
@@ -83,13 +83,13 @@
 @test "Pass when parsing a blank config file" {
    run bin/kubeval fixtures/blank.yaml
    [ "$status" -eq 0 ]
-   [ "$output" = "PASS - fixtures/blank.yaml contains an empty YAML document" ]
+   [ "${lines[0]}" = "PASS - fixtures/blank.yaml contains an empty YAML document" ]
  }
 
  @test "Pass when parsing a blank config file with a comment" {
    run bin/kubeval fixtures/comment.yaml
    [ "$status" -eq 0 ]
-   [ "$output" = "PASS - fixtures/comment.yaml contains an empty YAML document" ]
+   [ "${lines[0]}" = "PASS - fixtures/comment.yaml contains an empty YAML document" ]
  }
 
 @test "Return relevant error for YAML missing kind key" {
@@ -173,7 +173,7 @@
 @test "Does not print warnings if --quiet is supplied" {
   run bin/kubeval --ignore-missing-schemas --quiet fixtures/valid.yaml
   [ "$status" -eq 0 ]
-  [ "$output" = "PASS - fixtures/valid.yaml contains a valid ReplicationController (bob)" ]
+  [ "${lines[0]}" = "PASS - fixtures/valid.yaml contains a valid ReplicationController (bob)" ]
 }
 
 @test "Adjusts help string when invoked as a kubectl plugin" {
@@ -187,5 +187,174 @@
 @test "Only non-PASS messages are shown with --failures-only" {
   run bin/kubeval --failures-only fixtures/valid.yaml fixtures/invalid.yaml
   [ "$status" -eq 1 ]
-  [ "$output" = "WARN - fixtures/invalid.yaml contains an invalid ReplicationController (bob) - spec.replicas: Invalid type. Expected: [integer,null], given: string" ]
+  [ "${lines[0]}" = "WARN - fixtures/invalid.yaml:6:3 contains an invalid ReplicationController (bob) - spec.replicas: Invalid type. Expected: [integer,null], given: string" ]
+  [ "${lines[1]}" = "Summary: 1 valid, 1 invalid, 0 skipped across 2 files" ]
+}
+
+@test "Pass when validating the resources referenced by a kustomization" {
+  run bash -c "bin/kubeval --kustomizations fixtures/kustomize/overlay 2>/dev/null"
+  [ "$status" -eq 0 ]
+  [ "${lines[0]}" = "PASS - fixtures/kustomize/base/service.yaml contains a valid Service (kube-system.heapster)" ]
+  [ "${lines[1]}" = "PASS - fixtures/kustomize/overlay/replicationcontroller.yaml contains a valid ReplicationController (bob)" ]
+}
+
+@test "Notes the parts of a kustomization which are skipped on stderr" {
+  run bash -c "bin/kubeval --kustomizations fixtures/kustomize/overlay --output json 2>&1 >/dev/null"
+  [ "$status" -eq 0 ]
+  [ "$output" = "WARN - Skipping patches in fixtures/kustomize/overlay/kustomization.yaml as they require a kustomize build" ]
+}
+
+@test "Lists the optional checks with --list-checks" {
+  run bin/kubeval --list-checks --checks init-containers
+  [ "$status" -eq 0 ]
+  [[ "${lines[0]}" == "CHECK"*"SEVERITY"*"ENABLED"*"DESCRIPTION" ]]
+  [[ "${lines[1]}" == "init-containers"*"info"*"true"* ]]
+}
+
+@test "Uses --stdin-filename for manifests read from stdin" {
+  run bash -c "cat fixtures/valid.yaml | bin/kubeval --stdin-filename release.yaml -"
+  [ "$status" -eq 0 ]
+  [ "${lines[0]}" = "PASS - release.yaml contains a valid ReplicationController (bob)" ]
+}
+
+@test "Indexes multi-document stdin with --stdin-filename" {
+  run bash -c "cat fixtures/multi_valid.yaml | bin/kubeval --stdin-filename release.yaml -"
+  [ "$status" -eq 0 ]
+  [[ "${lines[0]}" == "PASS - release.yaml[0] contains a valid Service"* ]]
+}
+
+@test "Fail when stdin is not JSON with --stdin-format json" {
+  run bash -c "cat fixtures/valid.yaml | bin/kubeval --stdin-format json -"
+  [ "$status" -eq 1 ]
+  [ "$output" = "ERR  - Failed to decode JSON from stdin" ]
+}
+
+@test "Fail with a distinct exit code when no files are found" {
+  mkdir -p "$BATS_TMPDIR/empty"
+  run bin/kubeval -d "$BATS_TMPDIR/empty"
+  [ "$status" -eq 2 ]
+  [ "$output" = "ERR  - No files were found to validate. Pass --allow-empty if this is expected" ]
+}
+
+@test "Pass when no files are found with --allow-empty" {
+  mkdir -p "$BATS_TMPDIR/empty"
+  run bin/kubeval -d "$BATS_TMPDIR/empty" --allow-empty
+  [ "$status" -eq 0 ]
+}
+
+@test "Counts check findings without failing with --checks-dry-run" {
+  run bin/kubeval --checks-dry-run --checks container-names fixtures/checks/init_container_name.yaml
+  [ "$status" -eq 0 ]
+  [ "${lines[0]}" = "PASS - fixtures/checks/init_container_name.yaml contains a valid Pod (web)" ]
+  [[ "${lines[3]}" == "container-names"*"error"*"1" ]]
+}
+
+@test "Fail when a check reports an error severity finding" {
+  run bin/kubeval --checks container-names fixtures/checks/init_container_name.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" != *"PASS"* ]]
+  [[ "$output" == *"Summary: 0 valid, 1 invalid, 0 skipped"* ]]
+}
+
+@test "Writes one result file per input with --output-dir" {
+  rm -rf "$BATS_TMPDIR/results"
+  run bin/kubeval -o tap --output-dir "$BATS_TMPDIR/results" fixtures/valid.yaml fixtures/invalid.yaml
+  [ "$status" -eq 1 ]
+  [ "$output" = "" ]
+  [ -f "$BATS_TMPDIR/results/fixtures/valid.yaml.tap" ]
+  [ -f "$BATS_TMPDIR/results/fixtures/invalid.yaml.tap" ]
+}
+
+@test "Fail when --output-dir is used without a machine readable format" {
+  run bin/kubeval --output-dir "$BATS_TMPDIR/results" fixtures/valid.yaml
+  [ "$status" -eq 1 ]
+  [ "$output" = "ERR  - --output-dir requires --output json or --output tap" ]
+}
+
+@test "Reports memory use on stderr with --profile-memory" {
+  run bash -c "bin/kubeval --profile-memory fixtures/valid.yaml 2>&1 >/dev/null"
+  [ "$status" -eq 0 ]
+  [[ "${lines[0]}" == "PHASE"*"ALLOCATED"*"OBJECTS" ]]
+  [[ "$output" == *"Peak heap in use"* ]]
+}
+
+@test "Prints a summary of the results" {
+  run bin/kubeval fixtures/valid.yaml fixtures/blank.yaml fixtures/invalid.yaml
+  [ "$status" -eq 1 ]
+  [ "${lines[3]}" = "Summary: 1 valid, 1 invalid, 1 skipped across 3 files" ]
+}
+
+@test "Fail when an unknown output format is requested" {
+  run bin/kubeval -o jsn fixtures/valid.yaml
+  [ "$status" -eq 1 ]
+  [ "$output" = "ERR  - Unknown output format 'jsn', valid formats are: stdout, json, tap, junit, github, jsonl, template, csv, markdown" ]
+}
+
+@test "Writes results using a custom template" {
+  run bin/kubeval -o template --template '{{.Status}} {{.FileName}} {{.Kind}}' fixtures/valid.yaml
+  [ "$status" -eq 0 ]
+  [ "$output" = "valid fixtures/valid.yaml ReplicationController" ]
+}
+
+@test "Fail when an output template cannot be parsed" {
+  run bin/kubeval -o template --template '{{.Status' fixtures/valid.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" == "ERR  - Failed to parse output template: "* ]]
+}
+
+@test "Writes results as CSV" {
+  run bin/kubeval -o csv fixtures/valid.yaml
+  [ "$status" -eq 0 ]
+  [ "${lines[0]}" = "filename,kind,status,error" ]
+  [ "${lines[1]}" = "fixtures/valid.yaml,ReplicationController,valid," ]
+}
+
+@test "Does not print warnings for resources without a schema if --skip-warnings is supplied" {
+  run bin/kubeval --skip-kinds SealedSecret --skip-warnings fixtures/test_crd.yaml
+  [ "$status" -eq 0 ]
+  [ "$output" = "Summary: 0 valid, 0 invalid, 2 skipped across 1 file" ]
+}
+
+@test "Still fails on invalid resources if --skip-warnings is supplied" {
+  run bin/kubeval --skip-warnings fixtures/invalid.yaml
+  [ "$status" -eq 1 ]
+}
+
+@test "Writes results as a Markdown table" {
+  run bin/kubeval -o markdown fixtures/valid.yaml
+  [ "$status" -eq 0 ]
+  [ "${lines[0]}" = "| File | Kind | Status | Errors |" ]
+  [ "${lines[2]}" = "| fixtures/valid.yaml | ReplicationController | ✅ valid |  |" ]
+}
+
+@test "Groups results by file if --group-by-file is supplied" {
+  run bin/kubeval --group-by-file fixtures/valid.yaml fixtures/multi_valid.yaml
+  [ "$status" -eq 0 ]
+  [ "${lines[0]}" = "=== fixtures/valid.yaml ===" ]
+  [ "${lines[2]}" = "=== fixtures/multi_valid.yaml ===" ]
+}
+
+@test "Colors output if --color always is supplied" {
+  run bin/kubeval --color always fixtures/valid.yaml
+  [ "$status" -eq 0 ]
+  [[ "${lines[0]}" == $'\e[32mPASS\e[0m - fixtures/valid.yaml'* ]]
+}
+
+@test "Fail when an unknown color mode is requested" {
+  run bin/kubeval --color sometimes fixtures/valid.yaml
+  [ "$status" -eq 1 ]
+  [ "$output" = "ERR  - Unknown color mode 'sometimes', valid modes are: auto, always, never" ]
+}
+
+@test "Fails when --strict is combined with --schema-index" {
+  run bin/kubeval --strict --schema-index fixtures/schema-index/index.yaml fixtures/valid.yaml
+  [ "$status" -eq 1 ]
+  [ "${lines[0]}" = "ERR  - --strict cannot be combined with --schema-index, point the index at strict schemas instead" ]
+}
+
+@test "Reports a missing schema index once" {
+  run bin/kubeval --schema-index fixtures/schema-index/missing.yaml fixtures/valid.yaml fixtures/invalid.yaml
+  [ "$status" -eq 1 ]
+  [ "${#lines[@]}" -eq 1 ]
+  [[ "${lines[0]}" == "ERR  - Failed to read schema index fixtures/schema-index/missing.yaml"* ]]
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/instrumenta/kubeval/kubeval"
+)
+
+// checkReport counts the findings of the optional checks when running
+// with --checks-dry-run, in place of reporting them
+type checkReport struct {
+	checks  []kubeval.CheckInfo
+	byCheck map[string]int
+	byFile  map[string]int
+	files   []string
+}
+
+func newCheckReport() *checkReport {
+	var enabled []kubeval.CheckInfo
+	for _, c := range kubeval.ListChecks(config) {
+		if c.Enabled {
+			enabled = append(enabled, c)
+		}
+	}
+	return &checkReport{
+		checks:  enabled,
+		byCheck: map[string]int{},
+		byFile:  map[string]int{},
+	}
+}
+
+// record counts the check findings in results, then removes them so that
+// they affect neither the output nor the exit code
+func (c *checkReport) record(results []kubeval.ValidationResult) {
+	for i, r := range results {
+		var remaining []kubeval.Finding
+		for _, f := range r.Findings {
+			if !c.isCheck(f.CheckID) {
+				remaining = append(remaining, f)
+				continue
+			}
+			c.byCheck[f.CheckID]++
+			if _, seen := c.byFile[r.FileName]; !seen {
+				c.files = append(c.files, r.FileName)
+			}
+			c.byFile[r.FileName]++
+		}
+		results[i].Findings = remaining
+	}
+}
+
+func (c *checkReport) isCheck(id string) bool {
+	for _, check := range c.checks {
+		if check.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// print writes the number of findings for each check and each file to w
+func (c *checkReport) print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSEVERITY\tFINDINGS")
+	for _, check := range c.checks {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", check.ID, check.Severity, c.byCheck[check.ID])
+	}
+	if len(c.files) > 0 {
+		fmt.Fprintln(tw, "")
+		fmt.Fprintln(tw, "FILE\tFINDINGS")
+		for _, file := range c.files {
+			fmt.Fprintf(tw, "%s\t%d\n", file, c.byFile[file])
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,938 @@
+package kubeval
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Severity describes how serious a Finding reported by an optional check is
+type Severity string
+
+const (
+	// SeverityInfo findings are purely informational
+	SeverityInfo Severity = "info"
+	// SeverityWarning findings highlight likely problems, but do not fail validation
+	SeverityWarning Severity = "warning"
+	// SeverityError findings fail validation in the same way as schema errors
+	SeverityError Severity = "error"
+)
+
+// A Finding is a problem reported by one of the optional checks which
+// kubeval can run in addition to validating resources against their schema.
+// Schema errors whose severity was lowered using Config.ErrorSeverities are
+// also reported as findings, with the error type as their CheckID
+type Finding struct {
+	CheckID  string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// String returns a human readable representation of the finding
+func (f Finding) String() string {
+	if f.Path == "" {
+		return fmt.Sprintf("%s: %s", f.CheckID, f.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", f.CheckID, f.Path, f.Message)
+}
+
+// resource is a successfully decoded document, along with the index of
+// its ValidationResult in the results for the same input
+type resource struct {
+	index int
+	body  map[string]interface{}
+}
+
+// check is an optional, semantic check of resources which covers rules the
+// schemas permit but which are still likely to be a mistake.
+//
+// run is called once per resource, and is also given every resource from the
+// same input, or ManifestSet, so that checks which span several documents can
+// be written.
+// Findings returned without a Severity are assigned the check's default.
+type check struct {
+	ID          string
+	Description string
+	Severity    Severity
+	run         func(r resource, set []resource, config *Config) []Finding
+}
+
+// checks is the registry of all optional checks known to kubeval
+var checks = []check{
+	{
+		ID:          "init-containers",
+		Description: "Init containers do not reuse container names, only mount declared volumes and are not excessively numerous",
+		Severity:    SeverityInfo,
+		run:         checkInitContainers,
+	},
+	{
+		ID:          "affinity-weights",
+		Description: "Preferred affinity and anti-affinity terms have weights between 1 and 100, and term lists are not empty",
+		Severity:    SeverityError,
+		run:         checkAffinityWeights,
+	},
+	{
+		ID:          "service-selectors",
+		Description: "Services select the pods of at least one workload in the same manifest set",
+		Severity:    SeverityWarning,
+		run:         checkServiceSelectors,
+	},
+	{
+		ID:          "pod-host-settings",
+		Description: "Pods do not combine nodeName with scheduling constraints, or hostNetwork with conflicting host ports",
+		Severity:    SeverityWarning,
+		run:         checkPodHostSettings,
+	},
+	{
+		ID:          "default-namespace",
+		Description: "Namespaced resources do not target the default namespace",
+		Severity:    SeverityWarning,
+		run:         checkDefaultNamespace,
+	},
+	{
+		ID:          "container-names",
+		Description: "Container names are unique within a pod and are valid DNS labels",
+		Severity:    SeverityError,
+		run:         checkContainerNames,
+	},
+	{
+		ID:          "image-pull-secrets",
+		Description: "Pods only reference imagePullSecrets which are defined in the same manifest set",
+		Severity:    SeverityWarning,
+		run:         checkImagePullSecrets,
+	},
+	{
+		ID:          "hpa-replicas",
+		Description: "Workloads scaled by a HorizontalPodAutoscaler in the same manifest set do not also set spec.replicas",
+		Severity:    SeverityWarning,
+		run:         checkHPAReplicas,
+	},
+	{
+		ID:          "empty-dir",
+		Description: "emptyDir volumes have a sizeLimit which is a valid quantity and a supported medium",
+		Severity:    SeverityError,
+		run:         checkEmptyDir,
+	},
+	{
+		ID:          "rbac-wildcards",
+		Description: "Roles and ClusterRoles do not use wildcards in their rules' verbs, resources or apiGroups (an error with --strict)",
+		Severity:    SeverityWarning,
+		run:         checkRBACWildcards,
+	},
+	{
+		ID:          "service-accounts",
+		Description: "Pods only reference ServiceAccounts which are defined in the same manifest set",
+		Severity:    SeverityWarning,
+		run:         checkServiceAccounts,
+	},
+	{
+		ID:          "protocols",
+		Description: "Service, Endpoints and EndpointSlice ports use a supported protocol, and a well-known or domain-prefixed appProtocol (a warning)",
+		Severity:    SeverityError,
+		run:         checkProtocols,
+	},
+	{
+		ID:          "gateway-api-refs",
+		Description: "Gateway API routes and Gateways reference plausible kinds, and routes reference Gateways and Services defined in the same manifest set",
+		Severity:    SeverityWarning,
+		run:         checkGatewayAPIRefs,
+	},
+}
+
+// CheckInfo describes one of the optional checks available in kubeval
+type CheckInfo struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	Severity    Severity `json:"severity"`
+	Enabled     bool     `json:"enabled"`
+}
+
+// ListChecks returns a description of every optional check, including
+// whether it is enabled by config
+func ListChecks(config *Config) []CheckInfo {
+	enabled := enabledChecks(config)
+	infos := make([]CheckInfo, 0, len(checks))
+	for _, c := range checks {
+		info := CheckInfo{
+			ID:          c.ID,
+			Description: c.Description,
+			Severity:    c.Severity,
+		}
+		for _, e := range enabled {
+			if e.ID == c.ID {
+				info.Enabled = true
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// enabledChecks returns the checks which have been selected in config
+func enabledChecks(config *Config) []check {
+	enabled := []check{}
+	for _, c := range checks {
+		if in(config.Checks, "all") || in(config.Checks, c.ID) {
+			enabled = append(enabled, c)
+		}
+	}
+	return enabled
+}
+
+// validateCheckIDs returns an error if any of the requested checks are unknown
+func validateCheckIDs(config *Config) error {
+	known := []string{"all"}
+	for _, c := range checks {
+		known = append(known, c.ID)
+	}
+	for _, id := range config.Checks {
+		if !in(known, id) {
+			return fmt.Errorf("Unknown check '%s', valid checks are: %s", id, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// runChecks runs the enabled checks against the resources, recording any
+// findings on the corresponding results
+func runChecks(results []ValidationResult, set []resource, config *Config) {
+	for _, c := range enabledChecks(config) {
+		for _, r := range set {
+			kind, _ := getString(r.body, "kind")
+			for _, f := range c.run(r, set, config) {
+				f.CheckID = c.ID
+				if f.Severity == "" {
+					f.Severity = c.Severity
+				}
+				results[r.index].Findings = append(results[r.index].Findings, redactFinding(f, kind, config))
+			}
+		}
+	}
+}
+
+// podSpecPaths maps workload kinds to the location of their pod spec
+var podSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// podSpec returns the pod spec of a workload resource and the path at
+// which it was found, or nil if the resource does not contain one
+func podSpec(body map[string]interface{}) (map[string]interface{}, string) {
+	kind, _ := getString(body, "kind")
+	path, ok := podSpecPaths[kind]
+	if !ok {
+		return nil, ""
+	}
+	spec, err := getObjectAt(body, path)
+	if err != nil {
+		return nil, ""
+	}
+	return spec, strings.Join(path, ".")
+}
+
+// podTemplateLabels returns the labels which pods created by a workload
+// resource will have
+func podTemplateLabels(body map[string]interface{}) map[string]interface{} {
+	kind, _ := getString(body, "kind")
+	path, ok := podSpecPaths[kind]
+	if !ok {
+		return nil
+	}
+	labels, err := getObjectAt(body, append(append([]string{}, path[:len(path)-1]...), "metadata", "labels"))
+	if err != nil {
+		return nil
+	}
+	return labels
+}
+
+// clusterScopedKinds are the built-in kinds which do not belong to a namespace
+var clusterScopedKinds = []string{
+	"APIService",
+	"CertificateSigningRequest",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"ComponentStatus",
+	"CSIDriver",
+	"CSINode",
+	"CustomResourceDefinition",
+	"IngressClass",
+	"MutatingWebhookConfiguration",
+	"Namespace",
+	"Node",
+	"PersistentVolume",
+	"PodSecurityPolicy",
+	"PriorityClass",
+	"RuntimeClass",
+	"StorageClass",
+	"ValidatingWebhookConfiguration",
+	"VolumeAttachment",
+}
+
+// resourceNamespace returns the namespace of a resource, falling back to
+// the configured default namespace
+func resourceNamespace(body map[string]interface{}, config *Config) string {
+	if metadata, err := getObject(body, "metadata"); err == nil {
+		if namespace, _ := getString(metadata, "namespace"); namespace != "" {
+			return namespace
+		}
+	}
+	return config.DefaultNamespace
+}
+
+// namesOfKind returns the names of the resources of kind in namespace, and
+// whether any resources of that kind are in the set at all
+func namesOfKind(set []resource, kind, namespace string, config *Config) ([]string, bool) {
+	names := []string{}
+	found := false
+	for _, r := range set {
+		if k, _ := getString(r.body, "kind"); k != kind {
+			continue
+		}
+		found = true
+		if resourceNamespace(r.body, config) == namespace {
+			name, _ := getStringAt(r.body, []string{"metadata", "name"})
+			names = append(names, name)
+		}
+	}
+	return names, found
+}
+
+// resourceName returns a human readable description of a resource, such as
+// Deployment 'frontend'
+func resourceName(body map[string]interface{}) string {
+	kind, _ := getString(body, "kind")
+	name, _ := getStringAt(body, []string{"metadata", "name"})
+	return fmt.Sprintf("%s '%s'", kind, name)
+}
+
+// initContainerConsolidationThreshold is the number of init containers above
+// which a pod is reported as a candidate for consolidating them
+const initContainerConsolidationThreshold = 5
+
+func checkInitContainers(r resource, set []resource, config *Config) []Finding {
+	spec, path := podSpec(r.body)
+	if spec == nil {
+		return nil
+	}
+	initContainers := getObjects(spec, "initContainers")
+	if len(initContainers) == 0 {
+		return nil
+	}
+
+	containerNames := []string{}
+	for _, container := range getObjects(spec, "containers") {
+		name, _ := getString(container, "name")
+		containerNames = append(containerNames, name)
+	}
+	volumeNames := []string{}
+	for _, volume := range getObjects(spec, "volumes") {
+		name, _ := getString(volume, "name")
+		volumeNames = append(volumeNames, name)
+	}
+
+	findings := []Finding{}
+	for i, container := range initContainers {
+		containerPath := fmt.Sprintf("%s.initContainers.%d", path, i)
+		name, _ := getString(container, "name")
+		if name != "" && in(containerNames, name) {
+			findings = append(findings, Finding{
+				Path:    containerPath + ".name",
+				Message: fmt.Sprintf("Init container '%s' shares its name with a container", name),
+			})
+		}
+		for j, mount := range getObjects(container, "volumeMounts") {
+			volume, _ := getString(mount, "name")
+			if volume != "" && !in(volumeNames, volume) {
+				findings = append(findings, Finding{
+					Path:    fmt.Sprintf("%s.volumeMounts.%d.name", containerPath, j),
+					Message: fmt.Sprintf("Init container '%s' mounts volume '%s' which is not declared in the pod's volumes", name, volume),
+				})
+			}
+		}
+	}
+
+	if len(initContainers) > initContainerConsolidationThreshold {
+		findings = append(findings, Finding{
+			Path:    path + ".initContainers",
+			Message: fmt.Sprintf("Pod has %d init containers, consider consolidating them", len(initContainers)),
+		})
+	}
+	return findings
+}
+
+// affinityTypes are the kinds of affinity which can be set on a pod
+var affinityTypes = []string{"nodeAffinity", "podAffinity", "podAntiAffinity"}
+
+func checkAffinityWeights(r resource, set []resource, config *Config) []Finding {
+	spec, path := podSpec(r.body)
+	if spec == nil {
+		return nil
+	}
+	affinity, err := getObject(spec, "affinity")
+	if err != nil {
+		return nil
+	}
+
+	findings := []Finding{}
+	for _, affinityType := range affinityTypes {
+		terms, err := getObject(affinity, affinityType)
+		if err != nil {
+			continue
+		}
+		termsPath := fmt.Sprintf("%s.affinity.%s", path, affinityType)
+
+		for _, key := range []string{"preferredDuringSchedulingIgnoredDuringExecution", "requiredDuringSchedulingIgnoredDuringExecution"} {
+			if list, ok := terms[key].([]interface{}); ok && len(list) == 0 {
+				findings = append(findings, Finding{
+					Severity: SeverityWarning,
+					Path:     termsPath + "." + key,
+					Message:  "Term list is empty",
+				})
+			}
+		}
+		if required, err := getObject(terms, "requiredDuringSchedulingIgnoredDuringExecution"); err == nil {
+			if list, ok := required["nodeSelectorTerms"].([]interface{}); ok && len(list) == 0 {
+				findings = append(findings, Finding{
+					Severity: SeverityWarning,
+					Path:     termsPath + ".requiredDuringSchedulingIgnoredDuringExecution.nodeSelectorTerms",
+					Message:  "Term list is empty, so no node can satisfy it",
+				})
+			}
+		}
+
+		for i, term := range getObjects(terms, "preferredDuringSchedulingIgnoredDuringExecution") {
+			weight, ok := getNumber(term, "weight")
+			if ok && (weight < 1 || weight > 100) {
+				findings = append(findings, Finding{
+					Path:    fmt.Sprintf("%s.preferredDuringSchedulingIgnoredDuringExecution.%d.weight", termsPath, i),
+					Message: fmt.Sprintf("Weight %v must be in the range 1-100", weight),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func checkServiceSelectors(r resource, set []resource, config *Config) []Finding {
+	if kind, _ := getString(r.body, "kind"); kind != "Service" {
+		return nil
+	}
+	selector, err := getObjectAt(r.body, []string{"spec", "selector"})
+	if err != nil || len(selector) == 0 {
+		// Services without selectors have their endpoints managed manually
+		return nil
+	}
+	namespace := resourceNamespace(r.body, config)
+
+	var closest map[string]interface{}
+	closestMatches := -1
+	for _, other := range set {
+		labels := podTemplateLabels(other.body)
+		if labels == nil || resourceNamespace(other.body, config) != namespace {
+			continue
+		}
+		matches := 0
+		for key, value := range selector {
+			if labels[key] == value {
+				matches++
+			}
+		}
+		if matches == len(selector) {
+			return nil
+		}
+		if matches > closestMatches {
+			closest = other.body
+			closestMatches = matches
+		}
+	}
+	if closest == nil {
+		// There are no workloads in the set to compare against
+		return nil
+	}
+
+	return []Finding{{
+		Path:    "spec.selector",
+		Message: fmt.Sprintf("Selector does not match the pods of any workload in the manifest set, the closest match is %s", resourceName(closest)),
+	}}
+}
+
+func checkPodHostSettings(r resource, set []resource, config *Config) []Finding {
+	spec, path := podSpec(r.body)
+	if spec == nil {
+		return nil
+	}
+	findings := []Finding{}
+
+	if nodeName, _ := getString(spec, "nodeName"); nodeName != "" {
+		for _, key := range []string{"nodeSelector", "affinity"} {
+			if _, found := spec[key]; found {
+				findings = append(findings, Finding{
+					Path:    path + ".nodeName",
+					Message: fmt.Sprintf("nodeName bypasses the scheduler, so %s will not be taken into account", key),
+				})
+			}
+		}
+	}
+
+	if hostNetwork, _ := spec["hostNetwork"].(bool); hostNetwork {
+		seen := map[float64]string{}
+		for _, key := range []string{"initContainers", "containers"} {
+			for i, container := range getObjects(spec, key) {
+				for j, port := range getObjects(container, "ports") {
+					portPath := fmt.Sprintf("%s.%s.%d.ports.%d", path, key, i, j)
+					containerPort, _ := getNumber(port, "containerPort")
+					hostPort, ok := getNumber(port, "hostPort")
+					if !ok {
+						continue
+					}
+					if hostPort != containerPort {
+						findings = append(findings, Finding{
+							Path:    portPath + ".hostPort",
+							Message: fmt.Sprintf("With hostNetwork the container listens on the host directly, so hostPort %v must match containerPort %v", hostPort, containerPort),
+						})
+					} else {
+						findings = append(findings, Finding{
+							Severity: SeverityInfo,
+							Path:     portPath + ".hostPort",
+							Message:  "hostPort is redundant when hostNetwork is enabled",
+						})
+					}
+					if previous, duplicate := seen[hostPort]; duplicate {
+						findings = append(findings, Finding{
+							Path:    portPath + ".hostPort",
+							Message: fmt.Sprintf("hostPort %v is already used at %s", hostPort, previous),
+						})
+					}
+					seen[hostPort] = portPath
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func checkDefaultNamespace(r resource, set []resource, config *Config) []Finding {
+	kind, _ := getString(r.body, "kind")
+	if in(clusterScopedKinds, kind) || in(config.DefaultNamespaceExemptKinds, kind) {
+		return nil
+	}
+	namespace, _ := getStringAt(r.body, []string{"metadata", "namespace"})
+	if namespace == "default" {
+		return []Finding{{
+			Path:    "metadata.namespace",
+			Message: "Resource targets the default namespace",
+		}}
+	}
+	if namespace == "" && config.RequireExplicitNamespace {
+		return []Finding{{
+			Path:    "metadata",
+			Message: fmt.Sprintf("Resource does not set metadata.namespace, so will be deployed to the %s namespace", config.DefaultNamespace),
+		}}
+	}
+	return nil
+}
+
+// dnsLabelPattern matches an RFC 1123 DNS label
+var dnsLabelPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// dnsLabelMaxLength is the maximum length of an RFC 1123 DNS label
+const dnsLabelMaxLength = 63
+
+func checkContainerNames(r resource, set []resource, config *Config) []Finding {
+	spec, path := podSpec(r.body)
+	if spec == nil {
+		return nil
+	}
+	findings := []Finding{}
+	seen := map[string]string{}
+	for _, key := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		for i, container := range getObjects(spec, key) {
+			namePath := fmt.Sprintf("%s.%s.%d.name", path, key, i)
+			name, err := getString(container, "name")
+			if err != nil {
+				// Missing names are reported by the schema
+				continue
+			}
+			if len(name) > dnsLabelMaxLength || !dnsLabelPattern.MatchString(name) {
+				findings = append(findings, Finding{
+					Path:    namePath,
+					Message: fmt.Sprintf("Container name '%s' is not a valid DNS label (lowercase alphanumerics and '-', at most %d characters)", name, dnsLabelMaxLength),
+				})
+			}
+			if previous, duplicate := seen[name]; duplicate {
+				findings = append(findings, Finding{
+					Path:    namePath,
+					Message: fmt.Sprintf("Container name '%s' is already used at %s", name, previous),
+				})
+			}
+			seen[name] = namePath
+		}
+	}
+	return findings
+}
+
+func checkImagePullSecrets(r resource, set []resource, config *Config) []Finding {
+	if config.ExternalSecrets {
+		return nil
+	}
+	spec, path := podSpec(r.body)
+	if spec == nil {
+		return nil
+	}
+	pullSecrets := getObjects(spec, "imagePullSecrets")
+	if len(pullSecrets) == 0 {
+		return nil
+	}
+	secrets, found := namesOfKind(set, "Secret", resourceNamespace(r.body, config), config)
+	if !found {
+		// Without any Secrets in the set, they must be managed elsewhere
+		return nil
+	}
+
+	findings := []Finding{}
+	for i, pullSecret := range pullSecrets {
+		name, _ := getString(pullSecret, "name")
+		if name != "" && !in(secrets, name) {
+			findings = append(findings, Finding{
+				Path:    fmt.Sprintf("%s.imagePullSecrets.%d.name", path, i),
+				Message: fmt.Sprintf("Secret '%s' is not defined in the manifest set", name),
+			})
+		}
+	}
+	return findings
+}
+
+// scales returns whether the HorizontalPodAutoscaler hpa targets workload
+func scales(hpa, workload map[string]interface{}, config *Config) bool {
+	if kind, _ := getString(hpa, "kind"); kind != "HorizontalPodAutoscaler" {
+		return false
+	}
+	targetKind, _ := getStringAt(hpa, []string{"spec", "scaleTargetRef", "kind"})
+	targetName, _ := getStringAt(hpa, []string{"spec", "scaleTargetRef", "name"})
+	kind, _ := getString(workload, "kind")
+	name, _ := getStringAt(workload, []string{"metadata", "name"})
+	return kind == targetKind && name == targetName &&
+		resourceNamespace(hpa, config) == resourceNamespace(workload, config)
+}
+
+// hasReplicas returns whether a workload sets a static number of replicas
+func hasReplicas(workload map[string]interface{}) bool {
+	spec, err := getObject(workload, "spec")
+	if err != nil {
+		return false
+	}
+	_, found := spec["replicas"]
+	return found
+}
+
+func checkHPAReplicas(r resource, set []resource, config *Config) []Finding {
+	findings := []Finding{}
+	for _, other := range set {
+		if scales(r.body, other.body, config) && hasReplicas(other.body) {
+			findings = append(findings, Finding{
+				Path:    "spec.scaleTargetRef",
+				Message: fmt.Sprintf("Target %s also sets spec.replicas, which will fight this autoscaler", resourceName(other.body)),
+			})
+		}
+		if scales(other.body, r.body, config) && hasReplicas(r.body) {
+			findings = append(findings, Finding{
+				Path:    "spec.replicas",
+				Message: fmt.Sprintf("Replicas are managed by %s, consider removing spec.replicas", resourceName(other.body)),
+			})
+		}
+	}
+	return findings
+}
+
+// hugePagesMediumPattern matches the medium for huge pages of a specific size
+var hugePagesMediumPattern = regexp.MustCompile(`^HugePages-.+$`)
+
+func checkEmptyDir(r resource, set []resource, config *Config) []Finding {
+	spec, path := podSpec(r.body)
+	if spec == nil {
+		return nil
+	}
+	findings := []Finding{}
+	for i, volume := range getObjects(spec, "volumes") {
+		emptyDir, err := getObject(volume, "emptyDir")
+		if err != nil {
+			continue
+		}
+		volumePath := fmt.Sprintf("%s.volumes.%d.emptyDir", path, i)
+		name, _ := getString(volume, "name")
+
+		if sizeLimit, found := emptyDir["sizeLimit"]; found && sizeLimit != nil && !isQuantity(sizeLimit) {
+			findings = append(findings, Finding{
+				Path:    volumePath + ".sizeLimit",
+				Message: fmt.Sprintf("sizeLimit '%v' of volume '%s' is not a valid quantity", sizeLimit, name),
+			})
+		}
+		medium, _ := getString(emptyDir, "medium")
+		if medium != "" && medium != "Memory" && medium != "HugePages" && !hugePagesMediumPattern.MatchString(medium) {
+			findings = append(findings, Finding{
+				Path:    volumePath + ".medium",
+				Message: fmt.Sprintf("medium '%s' of volume '%s' is not supported, use Memory or leave it empty", medium, name),
+			})
+		}
+	}
+	return findings
+}
+
+func checkRBACWildcards(r resource, set []resource, config *Config) []Finding {
+	kind, _ := getString(r.body, "kind")
+	if kind != "Role" && kind != "ClusterRole" {
+		return nil
+	}
+	name, _ := getStringAt(r.body, []string{"metadata", "name"})
+	if in(config.RBACWildcardExemptRoles, name) {
+		return nil
+	}
+	severity := SeverityWarning
+	if config.Strict {
+		severity = SeverityError
+	}
+
+	findings := []Finding{}
+	for i, rule := range getObjects(r.body, "rules") {
+		for _, key := range []string{"apiGroups", "resources", "verbs"} {
+			values, _ := rule[key].([]interface{})
+			for _, value := range values {
+				if value == "*" {
+					findings = append(findings, Finding{
+						Severity: severity,
+						Path:     fmt.Sprintf("rules.%d.%s", i, key),
+						Message:  fmt.Sprintf("Rule grants all %s using a wildcard", key),
+					})
+					break
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func checkServiceAccounts(r resource, set []resource, config *Config) []Finding {
+	if config.ExternalServiceAccounts {
+		return nil
+	}
+	spec, path := podSpec(r.body)
+	if spec == nil {
+		return nil
+	}
+	name, _ := getString(spec, "serviceAccountName")
+	if name == "" || name == "default" {
+		// Every namespace has a default ServiceAccount
+		return nil
+	}
+	serviceAccounts, found := namesOfKind(set, "ServiceAccount", resourceNamespace(r.body, config), config)
+	if !found || in(serviceAccounts, name) {
+		return nil
+	}
+	return []Finding{{
+		Path:    path + ".serviceAccountName",
+		Message: fmt.Sprintf("ServiceAccount '%s' is not defined in the manifest set", name),
+	}}
+}
+
+// protocols are the port protocols supported by Kubernetes, which must be
+// written in upper case
+var protocols = []string{"TCP", "UDP", "SCTP"}
+
+// knownAppProtocols are the appProtocol values in common use without a
+// domain prefix. Prefixed values, such as kubernetes.io/h2c, are always allowed
+var knownAppProtocols = []string{"http", "https", "http2", "h2c", "grpc", "tcp", "udp", "sctp", "tls", "ws", "wss"}
+
+// portList is a list of ports, along with its path in a resource
+type portList struct {
+	path  string
+	ports []map[string]interface{}
+}
+
+// portLists returns the port lists of a Service, Endpoints or EndpointSlice
+func portLists(body map[string]interface{}) []portList {
+	kind, _ := getString(body, "kind")
+	switch kind {
+	case "Service":
+		spec, err := getObject(body, "spec")
+		if err != nil {
+			return nil
+		}
+		return []portList{{"spec.ports", getObjects(spec, "ports")}}
+	case "Endpoints":
+		lists := []portList{}
+		for i, subset := range getObjects(body, "subsets") {
+			lists = append(lists, portList{fmt.Sprintf("subsets.%d.ports", i), getObjects(subset, "ports")})
+		}
+		return lists
+	case "EndpointSlice":
+		return []portList{{"ports", getObjects(body, "ports")}}
+	}
+	return nil
+}
+
+func checkProtocols(r resource, set []resource, config *Config) []Finding {
+	findings := []Finding{}
+	for _, list := range portLists(r.body) {
+		for i, port := range list.ports {
+			portPath := fmt.Sprintf("%s.%d", list.path, i)
+			if protocol, err := getString(port, "protocol"); err == nil && !in(protocols, protocol) {
+				message := fmt.Sprintf("protocol '%s' of port %d is not supported, use one of %s", protocol, i, strings.Join(protocols, ", "))
+				if in(protocols, strings.ToUpper(protocol)) {
+					message = fmt.Sprintf("protocol '%s' of port %d must be upper case, use %s", protocol, i, strings.ToUpper(protocol))
+				}
+				findings = append(findings, Finding{
+					Path:    portPath + ".protocol",
+					Message: message,
+				})
+			}
+			if appProtocol, err := getString(port, "appProtocol"); err == nil && !strings.Contains(appProtocol, "/") && !in(knownAppProtocols, strings.ToLower(appProtocol)) {
+				findings = append(findings, Finding{
+					Severity: SeverityWarning,
+					Path:     portPath + ".appProtocol",
+					Message:  fmt.Sprintf("appProtocol '%s' of port %d is not a well-known protocol, prefix custom protocols with a domain such as example.com/%s", appProtocol, i, appProtocol),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// gatewayAPIGroup is the API group of the Gateway API resources
+const gatewayAPIGroup = "gateway.networking.k8s.io"
+
+// gatewayRouteKinds are the kinds of route defined by the Gateway API
+var gatewayRouteKinds = []string{"HTTPRoute", "GRPCRoute", "TCPRoute", "TLSRoute", "UDPRoute"}
+
+// gatewayRefKinds are the plausible kinds for a reference from a Gateway API
+// resource, keyed by the group of the reference. References to groups which
+// are not listed, such as those of implementation specific resources, are
+// not checked
+var gatewayRefKinds = map[string]map[string][]string{
+	"parentRefs": {
+		gatewayAPIGroup: {"Gateway"},
+		"":              {"Service"},
+	},
+	"backendRefs": {
+		"":                      {"Service"},
+		"multicluster.x-k8s.io": {"ServiceImport"},
+	},
+	"allowedRoutes": {
+		gatewayAPIGroup: gatewayRouteKinds,
+	},
+}
+
+// gatewayRef is a reference from a Gateway API resource, with the defaults
+// for its group and kind applied
+type gatewayRef struct {
+	path      string
+	group     string
+	kind      string
+	name      string
+	namespace string
+}
+
+// newGatewayRef reads a reference, using defaultGroup and defaultKind where
+// the reference does not set them, and the namespace of the referring
+// resource where it does not set one
+func newGatewayRef(ref map[string]interface{}, path, defaultGroup, defaultKind, namespace string) gatewayRef {
+	r := gatewayRef{
+		path:      path,
+		group:     defaultGroup,
+		kind:      defaultKind,
+		namespace: namespace,
+	}
+	if group, err := getString(ref, "group"); err == nil {
+		r.group = group
+	}
+	if kind, err := getString(ref, "kind"); err == nil {
+		r.kind = kind
+	}
+	if ns, err := getString(ref, "namespace"); err == nil && ns != "" {
+		r.namespace = ns
+	}
+	r.name, _ = getString(ref, "name")
+	return r
+}
+
+// gatewayRefs returns the references made by a Gateway API route or Gateway,
+// keyed by the type of reference
+func gatewayRefs(body map[string]interface{}, config *Config) map[string][]gatewayRef {
+	kind, _ := getString(body, "kind")
+	namespace := resourceNamespace(body, config)
+	spec, err := getObject(body, "spec")
+	if err != nil {
+		return nil
+	}
+
+	refs := map[string][]gatewayRef{}
+	if kind == "Gateway" {
+		for i, listener := range getObjects(spec, "listeners") {
+			allowedRoutes, err := getObject(listener, "allowedRoutes")
+			if err != nil {
+				continue
+			}
+			for j, routeKind := range getObjects(allowedRoutes, "kinds") {
+				path := fmt.Sprintf("spec.listeners.%d.allowedRoutes.kinds.%d", i, j)
+				refs["allowedRoutes"] = append(refs["allowedRoutes"], newGatewayRef(routeKind, path, gatewayAPIGroup, "", namespace))
+			}
+		}
+		return refs
+	}
+	if !in(gatewayRouteKinds, kind) {
+		return nil
+	}
+	for i, parentRef := range getObjects(spec, "parentRefs") {
+		path := fmt.Sprintf("spec.parentRefs.%d", i)
+		refs["parentRefs"] = append(refs["parentRefs"], newGatewayRef(parentRef, path, gatewayAPIGroup, "Gateway", namespace))
+	}
+	for i, rule := range getObjects(spec, "rules") {
+		for j, backendRef := range getObjects(rule, "backendRefs") {
+			path := fmt.Sprintf("spec.rules.%d.backendRefs.%d", i, j)
+			refs["backendRefs"] = append(refs["backendRefs"], newGatewayRef(backendRef, path, "", "Service", namespace))
+		}
+	}
+	return refs
+}
+
+func checkGatewayAPIRefs(r resource, set []resource, config *Config) []Finding {
+	refs := gatewayRefs(r.body, config)
+	findings := []Finding{}
+	for _, refType := range []string{"allowedRoutes", "parentRefs", "backendRefs"} {
+		for _, ref := range refs[refType] {
+			kinds, known := gatewayRefKinds[refType][ref.group]
+			if !known {
+				continue
+			}
+			if !in(kinds, ref.kind) {
+				group := ref.group
+				if group == "" {
+					group = "core"
+				}
+				findings = append(findings, Finding{
+					Path:    ref.path + ".kind",
+					Message: fmt.Sprintf("Kind '%s' in the %s group is not valid for %s, use one of %s", ref.kind, group, refType, strings.Join(kinds, ", ")),
+				})
+				continue
+			}
+			if refType == "allowedRoutes" || ref.kind == "ServiceImport" {
+				continue
+			}
+			names, found := namesOfKind(set, ref.kind, ref.namespace, config)
+			if found && !in(names, ref.name) {
+				findings = append(findings, Finding{
+					Path:    ref.path + ".name",
+					Message: fmt.Sprintf("%s '%s' in namespace '%s' is not defined in the manifest set", ref.kind, ref.name, ref.namespace),
+				})
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,666 @@
+package kubeval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+// runCheck runs a single check against the given YAML documents, returning
+// the findings recorded against each document in turn
+func runCheck(t *testing.T, config *Config, id string, documents ...string) [][]Finding {
+	config.Checks = []string{id}
+	results := make([]ValidationResult, len(documents))
+	set := make([]resource, len(documents))
+	for i, document := range documents {
+		var body map[string]interface{}
+		if err := yaml.Unmarshal([]byte(document), &body); err != nil {
+			t.Fatalf("Failed to decode test document: %v", err)
+		}
+		set[i] = resource{index: i, body: body}
+	}
+	runChecks(results, set, config)
+
+	findings := make([][]Finding, len(results))
+	for i, r := range results {
+		findings[i] = r.Findings
+	}
+	return findings
+}
+
+func TestValidateCheckIDs(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Checks = []string{"all", "init-containers"}
+	assert.NoError(t, validateCheckIDs(config))
+
+	config.Checks = []string{"not-a-check"}
+	assert.Error(t, validateCheckIDs(config))
+}
+
+func TestListChecks(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Checks = []string{"init-containers"}
+	infos := ListChecks(config)
+	assert.Equal(t, len(checks), len(infos))
+	for _, info := range infos {
+		assert.Equal(t, info.ID == "init-containers", info.Enabled, info.ID)
+	}
+
+	config.Checks = []string{"all"}
+	for _, info := range ListChecks(config) {
+		assert.True(t, info.Enabled, info.ID)
+	}
+}
+
+func TestCheckInitContainers(t *testing.T) {
+	var tests = []struct {
+		msg      string
+		document string
+		expected []string
+	}{
+		{
+			msg: "no init containers",
+			document: `
+kind: Pod
+spec:
+  containers:
+  - name: app
+`,
+			expected: []string{},
+		},
+		{
+			msg: "init container sharing a container name",
+			document: `
+kind: Pod
+spec:
+  initContainers:
+  - name: app
+  containers:
+  - name: app
+`,
+			expected: []string{"spec.initContainers.0.name"},
+		},
+		{
+			msg: "init container mounting an undeclared volume",
+			document: `
+kind: Deployment
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: setup
+        volumeMounts:
+        - name: config
+        - name: data
+      containers:
+      - name: app
+      volumes:
+      - name: config
+`,
+			expected: []string{"spec.template.spec.initContainers.0.volumeMounts.1.name"},
+		},
+		{
+			msg: "too many init containers",
+			document: `
+kind: Pod
+spec:
+  initContainers:
+  - name: one
+  - name: two
+  - name: three
+  - name: four
+  - name: five
+  - name: six
+`,
+			expected: []string{"spec.initContainers"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			findings := runCheck(t, NewDefaultConfig(), "init-containers", test.document)[0]
+			paths := []string{}
+			for _, f := range findings {
+				assert.Equal(t, "init-containers", f.CheckID)
+				assert.Equal(t, SeverityInfo, f.Severity)
+				paths = append(paths, f.Path)
+			}
+			assert.Equal(t, test.expected, paths)
+		})
+	}
+}
+
+func TestCheckAffinityWeights(t *testing.T) {
+	document := `
+kind: Deployment
+spec:
+  template:
+    spec:
+      affinity:
+        nodeAffinity:
+          requiredDuringSchedulingIgnoredDuringExecution:
+            nodeSelectorTerms: []
+          preferredDuringSchedulingIgnoredDuringExecution:
+          - weight: 100
+          - weight: 0
+        podAntiAffinity:
+          preferredDuringSchedulingIgnoredDuringExecution:
+          - weight: 101
+          requiredDuringSchedulingIgnoredDuringExecution: []
+`
+	findings := runCheck(t, NewDefaultConfig(), "affinity-weights", document)[0]
+	expected := map[string]Severity{
+		"spec.template.spec.affinity.nodeAffinity.requiredDuringSchedulingIgnoredDuringExecution.nodeSelectorTerms": SeverityWarning,
+		"spec.template.spec.affinity.nodeAffinity.preferredDuringSchedulingIgnoredDuringExecution.1.weight":         SeverityError,
+		"spec.template.spec.affinity.podAntiAffinity.requiredDuringSchedulingIgnoredDuringExecution":                SeverityWarning,
+		"spec.template.spec.affinity.podAntiAffinity.preferredDuringSchedulingIgnoredDuringExecution.0.weight":      SeverityError,
+	}
+	actual := map[string]Severity{}
+	for _, f := range findings {
+		actual[f.Path] = f.Severity
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestCheckServiceSelectors(t *testing.T) {
+	service := `
+kind: Service
+metadata:
+  name: frontend
+spec:
+  selector:
+    app: frontend
+    tier: web
+`
+	matching := `
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  template:
+    metadata:
+      labels:
+        app: frontend
+        tier: web
+`
+	closest := `
+kind: Deployment
+metadata:
+  name: frontend-v2
+spec:
+  template:
+    metadata:
+      labels:
+        app: frontend
+`
+	otherNamespace := `
+kind: Pod
+metadata:
+  name: frontend
+  namespace: other
+  labels:
+    app: frontend
+    tier: web
+`
+
+	findings := runCheck(t, NewDefaultConfig(), "service-selectors", service, matching, closest)
+	assert.Empty(t, findings[0])
+
+	findings = runCheck(t, NewDefaultConfig(), "service-selectors", service, closest, otherNamespace)
+	if assert.Len(t, findings[0], 1) {
+		assert.Equal(t, "spec.selector", findings[0][0].Path)
+		assert.Contains(t, findings[0][0].Message, "Deployment 'frontend-v2'")
+	}
+	assert.Empty(t, findings[1])
+	assert.Empty(t, findings[2])
+
+	findings = runCheck(t, NewDefaultConfig(), "service-selectors", service)
+	assert.Empty(t, findings[0])
+}
+
+func TestCheckPodHostSettings(t *testing.T) {
+	document := `
+kind: Pod
+spec:
+  nodeName: node-1
+  nodeSelector:
+    disk: ssd
+  hostNetwork: true
+  containers:
+  - name: web
+    ports:
+    - containerPort: 80
+      hostPort: 8080
+  - name: metrics
+    ports:
+    - containerPort: 8080
+      hostPort: 8080
+`
+	findings := runCheck(t, NewDefaultConfig(), "pod-host-settings", document)[0]
+	actual := []string{}
+	for _, f := range findings {
+		actual = append(actual, string(f.Severity)+" "+f.Path)
+	}
+	assert.Equal(t, []string{
+		"warning spec.nodeName",
+		"warning spec.containers.0.ports.0.hostPort",
+		"info spec.containers.1.ports.0.hostPort",
+		"warning spec.containers.1.ports.0.hostPort",
+	}, actual)
+}
+
+func TestCheckDefaultNamespace(t *testing.T) {
+	var tests = []struct {
+		msg      string
+		document string
+		require  bool
+		exempt   []string
+		expected []string
+	}{
+		{
+			msg: "explicit default namespace",
+			document: `
+kind: ConfigMap
+metadata:
+  namespace: default
+`,
+			expected: []string{"metadata.namespace"},
+		},
+		{
+			msg: "exempt kind",
+			document: `
+kind: ConfigMap
+metadata:
+  namespace: default
+`,
+			exempt:   []string{"ConfigMap"},
+			expected: []string{},
+		},
+		{
+			msg: "cluster scoped kind",
+			document: `
+kind: ClusterRole
+metadata:
+  name: reader
+`,
+			require:  true,
+			expected: []string{},
+		},
+		{
+			msg: "implicit namespace",
+			document: `
+kind: ConfigMap
+metadata:
+  name: settings
+`,
+			expected: []string{},
+		},
+		{
+			msg: "implicit namespace when explicit namespaces are required",
+			document: `
+kind: ConfigMap
+metadata:
+  name: settings
+`,
+			require:  true,
+			expected: []string{"metadata"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.RequireExplicitNamespace = test.require
+			config.DefaultNamespaceExemptKinds = test.exempt
+			paths := []string{}
+			for _, f := range runCheck(t, config, "default-namespace", test.document)[0] {
+				paths = append(paths, f.Path)
+			}
+			assert.Equal(t, test.expected, paths)
+		})
+	}
+}
+
+func TestCheckContainerNames(t *testing.T) {
+	document := `
+kind: Job
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: setup
+      containers:
+      - name: setup
+      - name: Not_A_Label
+      - name: worker
+      ephemeralContainers:
+      - name: worker
+`
+	paths := []string{}
+	for _, f := range runCheck(t, NewDefaultConfig(), "container-names", document)[0] {
+		assert.Equal(t, SeverityError, f.Severity)
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{
+		"spec.template.spec.containers.0.name",
+		"spec.template.spec.containers.1.name",
+		"spec.template.spec.ephemeralContainers.0.name",
+	}, paths)
+}
+
+func TestCheckImagePullSecrets(t *testing.T) {
+	deployment := `
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      imagePullSecrets:
+      - name: registry
+      - name: missing
+`
+	secret := `
+kind: Secret
+metadata:
+  name: registry
+`
+	otherNamespace := `
+kind: Secret
+metadata:
+  name: missing
+  namespace: other
+`
+
+	findings := runCheck(t, NewDefaultConfig(), "image-pull-secrets", deployment)
+	assert.Empty(t, findings[0])
+
+	findings = runCheck(t, NewDefaultConfig(), "image-pull-secrets", deployment, secret, otherNamespace)
+	if assert.Len(t, findings[0], 1) {
+		assert.Equal(t, "spec.template.spec.imagePullSecrets.1.name", findings[0][0].Path)
+	}
+
+	config := NewDefaultConfig()
+	config.ExternalSecrets = true
+	findings = runCheck(t, config, "image-pull-secrets", deployment, secret)
+	assert.Empty(t, findings[0])
+}
+
+func TestCheckHPAReplicas(t *testing.T) {
+	deployment := `
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`
+	hpa := `
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+`
+	otherNamespace := `
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+  namespace: other
+spec:
+  scaleTargetRef:
+    kind: Deployment
+    name: web
+`
+
+	findings := runCheck(t, NewDefaultConfig(), "hpa-replicas", deployment, hpa, otherNamespace)
+	if assert.Len(t, findings[0], 1) {
+		assert.Equal(t, "spec.replicas", findings[0][0].Path)
+		assert.Contains(t, findings[0][0].Message, "HorizontalPodAutoscaler 'web'")
+	}
+	if assert.Len(t, findings[1], 1) {
+		assert.Equal(t, "spec.scaleTargetRef", findings[1][0].Path)
+		assert.Contains(t, findings[1][0].Message, "Deployment 'web'")
+	}
+	assert.Empty(t, findings[2])
+
+	findings = runCheck(t, NewDefaultConfig(), "hpa-replicas", deployment)
+	assert.Empty(t, findings[0])
+}
+
+func TestCheckEmptyDir(t *testing.T) {
+	document := `
+kind: Pod
+spec:
+  volumes:
+  - name: cache
+    emptyDir:
+      sizeLimit: 500Mi
+      medium: Memory
+  - name: scratch
+    emptyDir:
+      sizeLimit: 500 megabytes
+      medium: disk
+  - name: numeric
+    emptyDir:
+      sizeLimit: 1000
+  - name: config
+    configMap:
+      name: config
+`
+	paths := []string{}
+	for _, f := range runCheck(t, NewDefaultConfig(), "empty-dir", document)[0] {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{
+		"spec.volumes.1.emptyDir.sizeLimit",
+		"spec.volumes.1.emptyDir.medium",
+	}, paths)
+}
+
+func TestCheckRBACWildcards(t *testing.T) {
+	role := `
+kind: ClusterRole
+metadata:
+  name: deployer
+rules:
+- apiGroups: ["apps"]
+  resources: ["deployments"]
+  verbs: ["get", "list"]
+- apiGroups: ["*"]
+  resources: ["*"]
+  verbs: ["get", "*"]
+`
+	clusterAdmin := `
+kind: ClusterRole
+metadata:
+  name: cluster-admin
+rules:
+- apiGroups: ["*"]
+  resources: ["*"]
+  verbs: ["*"]
+`
+
+	findings := runCheck(t, NewDefaultConfig(), "rbac-wildcards", role, clusterAdmin)
+	paths := []string{}
+	for _, f := range findings[0] {
+		assert.Equal(t, SeverityWarning, f.Severity)
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"rules.1.apiGroups", "rules.1.resources", "rules.1.verbs"}, paths)
+	assert.Empty(t, findings[1])
+
+	config := NewDefaultConfig()
+	config.Strict = true
+	for _, f := range runCheck(t, config, "rbac-wildcards", role)[0] {
+		assert.Equal(t, SeverityError, f.Severity)
+	}
+}
+
+func TestCheckServiceAccounts(t *testing.T) {
+	job := `
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          serviceAccountName: backup
+`
+	defaultAccount := `
+kind: Pod
+metadata:
+  name: web
+spec:
+  serviceAccountName: default
+`
+	other := `
+kind: ServiceAccount
+metadata:
+  name: web
+`
+	serviceAccount := `
+kind: ServiceAccount
+metadata:
+  name: backup
+`
+
+	findings := runCheck(t, NewDefaultConfig(), "service-accounts", job, defaultAccount)
+	assert.Empty(t, findings[0])
+
+	findings = runCheck(t, NewDefaultConfig(), "service-accounts", job, defaultAccount, other)
+	if assert.Len(t, findings[0], 1) {
+		assert.Equal(t, "spec.jobTemplate.spec.template.spec.serviceAccountName", findings[0][0].Path)
+	}
+	assert.Empty(t, findings[1])
+
+	findings = runCheck(t, NewDefaultConfig(), "service-accounts", job, serviceAccount)
+	assert.Empty(t, findings[0])
+
+	config := NewDefaultConfig()
+	config.ExternalServiceAccounts = true
+	findings = runCheck(t, config, "service-accounts", job, other)
+	assert.Empty(t, findings[0])
+}
+
+func TestCheckProtocols(t *testing.T) {
+	service := `
+kind: Service
+spec:
+  ports:
+  - port: 80
+    protocol: TCP
+    appProtocol: HTTP
+  - port: 53
+    protocol: udp
+  - port: 8080
+    protocol: QUIC
+    appProtocol: example.com/custom
+  - port: 9000
+    appProtocol: thrift
+`
+	endpoints := `
+kind: Endpoints
+subsets:
+- ports:
+  - port: 80
+- ports:
+  - port: 53
+    protocol: Udp
+`
+
+	findings := runCheck(t, NewDefaultConfig(), "protocols", service, endpoints)
+	assert.Equal(t, []Finding{
+		{
+			CheckID:  "protocols",
+			Severity: SeverityError,
+			Path:     "spec.ports.1.protocol",
+			Message:  "protocol 'udp' of port 1 must be upper case, use UDP",
+		},
+		{
+			CheckID:  "protocols",
+			Severity: SeverityError,
+			Path:     "spec.ports.2.protocol",
+			Message:  "protocol 'QUIC' of port 2 is not supported, use one of TCP, UDP, SCTP",
+		},
+		{
+			CheckID:  "protocols",
+			Severity: SeverityWarning,
+			Path:     "spec.ports.3.appProtocol",
+			Message:  "appProtocol 'thrift' of port 3 is not a well-known protocol, prefix custom protocols with a domain such as example.com/thrift",
+		},
+	}, findings[0])
+	assert.Len(t, findings[1], 1)
+	assert.Equal(t, "subsets.1.ports.0.protocol", findings[1][0].Path)
+}
+
+func TestCheckGatewayAPIRefs(t *testing.T) {
+	gateway := `
+apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: public
+spec:
+  listeners:
+  - name: http
+    allowedRoutes:
+      kinds:
+      - kind: HTTPRoute
+      - kind: Ingress
+`
+	route := `
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+spec:
+  parentRefs:
+  - name: public
+  - name: internal
+  - name: public
+    kind: Ingress
+  rules:
+  - backendRefs:
+    - name: web
+      port: 80
+    - name: api
+      port: 80
+    - name: bucket
+      group: storage.example.com
+      kind: Bucket
+    - name: web
+      kind: Deployment
+`
+	service := `
+kind: Service
+metadata:
+  name: web
+`
+
+	findings := runCheck(t, NewDefaultConfig(), "gateway-api-refs", gateway, route, service)
+	paths := []string{}
+	for _, f := range findings[0] {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"spec.listeners.0.allowedRoutes.kinds.1.kind"}, paths)
+
+	paths = []string{}
+	for _, f := range findings[1] {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{
+		"spec.parentRefs.1.name",
+		"spec.parentRefs.2.kind",
+		"spec.rules.0.backendRefs.1.name",
+		"spec.rules.0.backendRefs.3.kind",
+	}, paths)
+	assert.Equal(t, "Kind 'Deployment' in the core group is not valid for backendRefs, use one of Service", findings[1][3].Message)
+	assert.Empty(t, findings[2])
+
+	// Without any Gateways or Services in the set, they must be managed elsewhere
+	findings = runCheck(t, NewDefaultConfig(), "gateway-api-refs", route)
+	assert.Len(t, findings[0], 2)
+}
@@ -0,0 +1,47 @@
+package kubeval
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// ColorAuto colors output written to a terminal, unless the NO_COLOR
+	// environment variable is set
+	ColorAuto = "auto"
+	// ColorAlways always colors output
+	ColorAlways = "always"
+	// ColorNever never colors output
+	ColorNever = "never"
+)
+
+// UseColor returns whether output written to w should be colored in mode,
+// which is one of ColorAuto, ColorAlways or ColorNever. An empty mode is
+// treated as ColorAuto
+func UseColor(mode string, w io.Writer) (bool, error) {
+	switch mode {
+	case ColorAlways:
+		return true, nil
+	case ColorNever:
+		return false, nil
+	case "", ColorAuto:
+		// see https://no-color.org
+		if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+			return false, nil
+		}
+		return isTerminal(w), nil
+	default:
+		return false, fmt.Errorf("Unknown color mode '%s', valid modes are: %s, %s, %s", mode, ColorAuto, ColorAlways, ColorNever)
+	}
+}
+
+// isTerminal returns whether w is a terminal, rather than a file or a pipe
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
@@ -12,6 +12,14 @@ const DefaultSchemaLocation = "https://kubernetesjsonschema.dev"
 // OpenShiftSchemaLocation is the alternative location for OpenShift specific schemas
 const OpenShiftSchemaLocation = "https://raw.githubusercontent.com/garethr/openshift-json-schema/master"
 
+const (
+	// InputFormatYAML splits input into YAML documents, each of which may
+	// also be written as JSON
+	InputFormatYAML = "yaml"
+	// InputFormatJSON treats input as a single JSON document
+	InputFormatJSON = "json"
+)
+
 // A Config object contains various configuration data for kubeval
 type Config struct {
 	// DefaultNamespace is the namespace to assume in resources
@@ -41,6 +49,12 @@ type Config struct {
 	// the schema. The API allows them, but kubectl does not
 	Strict bool
 
+	// StrictStatus controls how the status of resources exported from a
+	// cluster is validated in strict mode, as one of StrictStatusValidate,
+	// StrictStatusLenient or StrictStatusStrip. If empty, status is validated
+	// in the same way as every other field
+	StrictStatus string
+
 	// IgnoreMissingSchemas tells kubeval whether to skip validation
 	// for resource definitions without an available schema
 	IgnoreMissingSchemas bool
@@ -59,10 +73,36 @@ type Config struct {
 	// FileName is the name to be displayed when testing manifests read from stdin
 	FileName string
 
+	// InputFormat forces the parser used for input, either InputFormatYAML
+	// or InputFormatJSON. If empty, input is treated as YAML
+	InputFormat string
+
 	// OutputFormat is the name of the output formatter which will be used when
 	// reporting results to the user.
 	OutputFormat string
 
+	// OutputTemplate is the text/template used to write each result when
+	// OutputFormat is template
+	OutputTemplate string
+
+	// OutputJSONFile is the path of a file to which results are also written
+	// as JSON, alongside the output selected by OutputFormat
+	OutputJSONFile string
+
+	// OutputDir is a directory to which the results for each input file are
+	// written, in a file of their own, instead of to stdout. The directory
+	// mirrors the layout of the inputs. Requires OutputFormat json or tap
+	OutputDir string
+
+	// RunMetadata tells kubeval to include a run ID and the UTC time the run
+	// started in JSON output, wrapping the results, and in the properties of
+	// JUnit output. Other formats cannot include it
+	RunMetadata bool
+
+	// RunID is the run ID to include in JSON and JUnit output, such as a CI
+	// build number. Setting it implies RunMetadata. If empty, one is generated
+	RunID string
+
 	// Quiet indicates whether non-results output should be emitted to the applications
 	// log.
 	Quiet bool
@@ -73,14 +113,90 @@ type Config struct {
 
 	// Output only those files that do not PASS
 	FailuresOnly bool
+
+	// SkipWarnings leaves resources which were not validated against a
+	// schema, and empty documents, out of the output. Validation errors are
+	// still reported
+	SkipWarnings bool
+
+	// GroupByFile tells the stdout output to print the results grouped under
+	// a header for each file, once every file has been validated, instead of
+	// as each resource is validated
+	GroupByFile bool
+
+	// SummaryByNamespace adds the counts of valid, invalid and skipped
+	// resources in each namespace to the stdout summary, and a summary
+	// object with the same counts to JSON output, wrapping the results.
+	// Cluster-scoped resources are counted under "(cluster)"
+	SummaryByNamespace bool
+
+	// JUnitPerError tells the junit output to report each error as a test
+	// case of its own, instead of with a test case for each resource
+	JUnitPerError bool
+
+	// Color controls whether the stdout output is colored, as one of
+	// ColorAuto, ColorAlways or ColorNever. If empty, ColorAuto is used
+	Color string
+
+	// Checks is a list of the optional checks to run against resources in
+	// addition to schema validation. The value "all" enables every check
+	Checks []string
+
+	// RequireExplicitNamespace tells the default-namespace check to also
+	// report namespaced resources which do not set `metadata:namespace`
+	RequireExplicitNamespace bool
+
+	// DefaultNamespaceExemptKinds is a list of kubernetes resource types which
+	// the default-namespace check should not report
+	DefaultNamespaceExemptKinds []string
+
+	// RBACWildcardExemptRoles is a list of the names of Roles and ClusterRoles
+	// which are intentionally broad, so the rbac-wildcards check should not report
+	RBACWildcardExemptRoles []string
+
+	// ExternalSecrets indicates that Secrets are managed outside of the
+	// manifests being validated, so checks should not expect to find them
+	ExternalSecrets bool
+
+	// ExternalServiceAccounts indicates that ServiceAccounts are managed outside
+	// of the manifests being validated, so checks should not expect to find them
+	ExternalServiceAccounts bool
+
+	// ErrorSeverities maps schema error types, such as `required` or
+	// `additional_property_not_allowed`, to the severity they should be
+	// reported with. Errors mapped to warning or info do not fail validation
+	ErrorSeverities map[string]string
+
+	// RedactKinds is a list of kubernetes resource types whose values should
+	// be masked in validation errors and check findings, as they may contain
+	// sensitive data
+	RedactKinds []string
+
+	// RedactFields is a list of dot-separated field paths, such as
+	// `spec.template.spec.containers`, whose values should be masked in
+	// validation errors and check findings for resources of any kind
+	RedactFields []string
+
+	// SchemaIndex is the location of a JSON or YAML file mapping each
+	// apiVersion/kind to its schema. When set, schemas are resolved using
+	// the index instead of SchemaLocation and AdditionalSchemaLocations, and
+	// it cannot be combined with Strict, OpenShift or a KubernetesVersion
+	SchemaIndex string
+
+	// schemaIndex caches the decoded contents of SchemaIndex
+	schemaIndex *schemaIndex
 }
 
 // NewDefaultConfig creates a Config with default values
 func NewDefaultConfig() *Config {
 	return &Config{
-		DefaultNamespace:  "default",
-		FileName:          "stdin",
-		KubernetesVersion: "master",
+		DefaultNamespace:        "default",
+		FileName:                "stdin",
+		KubernetesVersion:       "master",
+		StrictStatus:            StrictStatusLenient,
+		Color:                   ColorAuto,
+		RedactKinds:             []string{"Secret"},
+		RBACWildcardExemptRoles: []string{"cluster-admin"},
 	}
 }
 
@@ -91,16 +207,37 @@ func AddKubevalFlags(cmd *cobra.Command, config *Config) *cobra.Command {
 	cmd.Flags().BoolVar(&config.IgnoreMissingSchemas, "ignore-missing-schemas", false, "Skip validation for resource definitions without a schema")
 	cmd.Flags().BoolVar(&config.OpenShift, "openshift", false, "Use OpenShift schemas instead of upstream Kubernetes")
 	cmd.Flags().BoolVar(&config.Strict, "strict", false, "Disallow additional properties not in schema")
+	cmd.Flags().StringVar(&config.StrictStatus, "strict-status", StrictStatusLenient, fmt.Sprintf("How status is validated with --strict. Options are: %s (allow properties not in the schema), %s (remove status before validating) and %s (validate strictly)", StrictStatusLenient, StrictStatusStrip, StrictStatusValidate))
 	cmd.Flags().StringVarP(&config.FileName, "filename", "f", "stdin", "filename to be displayed when testing manifests read from stdin")
 	cmd.Flags().StringSliceVar(&config.KindsToSkip, "skip-kinds", []string{}, "Comma-separated list of case-sensitive kinds to skip when validating against schemas")
 	cmd.Flags().StringSliceVar(&config.KindsToReject, "reject-kinds", []string{}, "Comma-separated list of case-sensitive kinds to prohibit validating against schemas")
 	cmd.Flags().StringVarP(&config.SchemaLocation, "schema-location", "s", "", "Base URL used to download schemas. Can also be specified with the environment variable KUBEVAL_SCHEMA_LOCATION.")
 	cmd.Flags().StringSliceVar(&config.AdditionalSchemaLocations, "additional-schema-locations", []string{}, "Comma-seperated list of secondary base URLs used to download schemas")
-	cmd.Flags().StringVarP(&config.KubernetesVersion, "kubernetes-version", "v", "master", "Version of Kubernetes to validate against")
+	cmd.Flags().StringVar(&config.SchemaIndex, "schema-index", "", "Path or URL of a JSON or YAML file mapping each apiVersion/kind to the location of its schema, used instead of --schema-location. Cannot be combined with --strict, --openshift or --kubernetes-version")
+	cmd.Flags().StringVarP(&config.KubernetesVersion, "kubernetes-version", "v", "master", "Version of Kubernetes to validate against. Use master or prerelease, or a version such as 1.22.0-rc.0, for unreleased schemas")
 	cmd.Flags().StringVarP(&config.OutputFormat, "output", "o", "", fmt.Sprintf("The format of the output of this script. Options are: %v", validOutputs()))
+	cmd.Flags().StringVar(&config.OutputTemplate, "template", "", "Go text/template used to write each result with --output template, with the fields FileName, Kind, QualifiedName, Status, Errors and Findings")
+	cmd.Flags().StringVar(&config.OutputJSONFile, "output-json", "", "Also write the results as JSON to this file, alongside the output selected by --output")
+	cmd.Flags().StringVar(&config.OutputDir, "output-dir", "", "Write the results for each input file to a file of its own in this directory, mirroring the layout of the inputs, instead of to stdout. Requires --output json or tap")
+	cmd.Flags().BoolVar(&config.RunMetadata, "run-metadata", false, "Include a run ID and timestamp in JSON output, which wraps the results in an object, and in the properties of JUnit output")
+	cmd.Flags().StringVar(&config.RunID, "run-id", "", "Run ID to include in JSON and JUnit output, such as a CI build number. Implies --run-metadata, which otherwise generates one")
 	cmd.Flags().BoolVar(&config.Quiet, "quiet", false, "Silences any output aside from the direct results")
 	cmd.Flags().BoolVar(&config.InsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "If true, the server's certificate will not be checked for validity. This will make your HTTPS connections insecure")
 	cmd.Flags().BoolVar(&config.FailuresOnly, "failures-only", false, "If true, only files that fail validation will be included in the output.")
+	cmd.Flags().BoolVar(&config.SkipWarnings, "skip-warnings", false, "If true, resources which were not validated against a schema, and empty documents, are left out of the output")
+	cmd.Flags().BoolVar(&config.GroupByFile, "group-by-file", false, "Print the results of the stdout output grouped under a header for each file, once every file has been validated. Requires --output stdout")
+	cmd.Flags().BoolVar(&config.SummaryByNamespace, "summary-by-namespace", false, "Add the counts of valid, invalid and skipped resources in each namespace to the summary, and a summary object to JSON output. Cluster-scoped resources are counted under (cluster). Requires --output stdout or json, or --output-json")
+	cmd.Flags().BoolVar(&config.JUnitPerError, "junit-per-error", false, "With --output junit, report each error as a test case of its own instead of as a failure of the resource's test case")
+	cmd.Flags().StringVar(&config.Color, "color", ColorAuto, fmt.Sprintf("When to color output. Options are: %s (when writing to a terminal and NO_COLOR is not set), %s and %s", ColorAuto, ColorAlways, ColorNever))
+	cmd.Flags().StringSliceVar(&config.Checks, "checks", []string{}, "Comma-separated list of optional checks to run against resources, or 'all' to run every check. Checks which compare resources see those of every file passed")
+	cmd.Flags().BoolVar(&config.RequireExplicitNamespace, "require-explicit-namespace", false, "Make the default-namespace check also report namespaced resources which do not set metadata:namespace")
+	cmd.Flags().StringSliceVar(&config.DefaultNamespaceExemptKinds, "default-namespace-exempt-kinds", []string{}, "Comma-separated list of case-sensitive kinds which the default-namespace check should not report")
+	cmd.Flags().StringSliceVar(&config.RBACWildcardExemptRoles, "rbac-wildcard-exempt-roles", []string{"cluster-admin"}, "Comma-separated list of names of intentionally broad Roles and ClusterRoles which the rbac-wildcards check should not report")
+	cmd.Flags().BoolVar(&config.ExternalSecrets, "external-secrets", false, "Secrets are managed outside of the manifests being validated, so checks should not expect to find them")
+	cmd.Flags().BoolVar(&config.ExternalServiceAccounts, "external-service-accounts", false, "ServiceAccounts are managed outside of the manifests being validated, so checks should not expect to find them")
+	cmd.Flags().StringToStringVar(&config.ErrorSeverities, "error-severity", map[string]string{}, "Comma-separated list of schema error type=severity pairs, such as additional_property_not_allowed=warning, to change the severity errors are reported with")
+	cmd.Flags().StringSliceVar(&config.RedactKinds, "redact-kinds", []string{"Secret"}, "Comma-separated list of case-sensitive kinds whose values should be masked in validation errors and check findings")
+	cmd.Flags().StringSliceVar(&config.RedactFields, "redact-fields", []string{}, "Comma-separated list of dot-separated field paths whose values should be masked in validation errors and check findings")
 
 	return cmd
 }
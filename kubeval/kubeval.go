@@ -2,9 +2,11 @@ package kubeval
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/go-multierror"
@@ -12,6 +14,22 @@ import (
 	"sigs.k8s.io/yaml"
 )
 
+func init() {
+	// Without forcing these types the schema fails to load
+	// Need to Work out proper handling for these types
+	gojsonschema.FormatCheckers.Add("int64", ValidFormat{})
+	gojsonschema.FormatCheckers.Add("byte", ValidFormat{})
+	gojsonschema.FormatCheckers.Add("int32", ValidFormat{})
+	gojsonschema.FormatCheckers.Add("int-or-string", ValidFormat{})
+}
+
+// helmSourcePatterns match the comment which Helm adds to the start of each
+// rendered template, keyed by line break
+var helmSourcePatterns = map[string]*regexp.Regexp{
+	"\n":   regexp.MustCompile(`^(?:---` + "\n" + `)?# Source: (.*)`),
+	"\r\n": regexp.MustCompile(`^(?:---` + "\r\n" + `)?# Source: (.*)`),
+}
+
 // ValidFormat is a type for quickly forcing
 // new formats on the gojsonschema loader
 type ValidFormat struct{}
@@ -32,6 +50,7 @@ type ValidationResult struct {
 	Errors                 []gojsonschema.ResultError
 	ResourceName           string
 	ResourceNamespace      string
+	Findings               []Finding
 }
 
 // VersionKind returns a string representation of this result's apiVersion and kind
@@ -41,13 +60,51 @@ func (v *ValidationResult) VersionKind() string {
 
 // QualifiedName returns a string of the [namespace.]name of the k8s resource
 func (v *ValidationResult) QualifiedName() string {
-	if v.ResourceName == "" {
+	return qualifyName(v.ResourceName, v.ResourceNamespace)
+}
+
+// QualifyName returns a string of the [namespace.]name of a decoded k8s
+// resource, in the same format as ValidationResult.QualifiedName
+func QualifyName(obj map[string]interface{}) string {
+	return qualifyName(metadataName(obj))
+}
+
+func qualifyName(name, namespace string) string {
+	if name == "" {
 		return "unknown"
-	} else if v.ResourceNamespace == "" {
-		return v.ResourceName
+	} else if namespace == "" {
+		return name
 	} else {
-		return fmt.Sprintf("%s.%s", v.ResourceNamespace, v.ResourceName)
+		return fmt.Sprintf("%s.%s", namespace, name)
+	}
+}
+
+// metadataName returns the name and namespace from the metadata of a
+// decoded k8s resource. Names which will be generated are marked as such
+func metadataName(obj map[string]interface{}) (string, string) {
+	metadata, _ := getObject(obj, "metadata")
+	if metadata == nil {
+		return "", ""
 	}
+	namespace, _ := getString(metadata, "namespace")
+	name, _ := getString(metadata, "name")
+	generateName, _ := getString(metadata, "generateName")
+
+	if len(name) == 0 && len(generateName) > 0 {
+		return fmt.Sprintf("%s{{ generateName }}", generateName), namespace
+	}
+	return name, namespace
+}
+
+// prereleaseVersionPattern matches Kubernetes release candidates, alphas
+// and betas, such as 1.22.0-rc.0
+var prereleaseVersionPattern = regexp.MustCompile(`^\d+\.\d+(\.\d+)?-(alpha|beta|rc)(\.?\d+)?$`)
+
+// IsPrereleaseVersion returns whether version refers to unreleased
+// Kubernetes schemas: master, prerelease, or an alpha, beta or release
+// candidate version. These are all validated against the schemas from master
+func IsPrereleaseVersion(version string) bool {
+	return version == "master" || version == "prerelease" || prereleaseVersionPattern.MatchString(version)
 }
 
 func determineSchemaURL(baseURL, kind, apiVersion string, config *Config) string {
@@ -56,10 +113,11 @@ func determineSchemaURL(baseURL, kind, apiVersion string, config *Config) string
 	// use that to format the URL to match the required specification.
 
 	// Most of the directories which store the schemas are prefixed with a v so as to
-	// match the tagging in the Kubernetes repository, apart from master.
-	normalisedVersion := config.KubernetesVersion
-	if normalisedVersion != "master" {
-		normalisedVersion = "v" + normalisedVersion
+	// match the tagging in the Kubernetes repository, apart from master. There are
+	// no schemas for pre-releases, so the closest match is those from master.
+	normalisedVersion := "master"
+	if !IsPrereleaseVersion(config.KubernetesVersion) {
+		normalisedVersion = "v" + config.KubernetesVersion
 	}
 
 	strictSuffix := ""
@@ -108,33 +166,72 @@ func determineSchemaBaseURL(config *Config) string {
 	return DefaultSchemaLocation
 }
 
+// document is a single YAML document from the input, which is decoded
+// once and then reused to detect lists, validate and run the checks
+type document struct {
+	data []byte
+	body map[string]interface{}
+	err  error
+
+	// src is the input the document was decoded from, and path the keys and
+	// indexes of the document within it, used to find the position of errors
+	src  *source
+	path []string
+}
+
+// decodeDocument decodes data into a document
+func decodeDocument(data []byte) document {
+	doc := document{data: data}
+	doc.err = yaml.Unmarshal(data, &doc.body)
+	return doc
+}
+
+// empty returns whether the document has no content at all
+func (d document) empty() bool {
+	return len(d.data) == 0 && d.body == nil
+}
+
+// listItems returns the items of a document which is a list, such as the
+// output of `kubectl get -o yaml`. Keys are matched case-insensitively, and
+// kind and version must be strings, as when decoding into a struct.
+func listItems(doc document) ([]interface{}, bool) {
+	if doc.err != nil {
+		return nil, false
+	}
+	var items []interface{}
+	for key, value := range doc.body {
+		switch {
+		case strings.EqualFold(key, "items"):
+			if value == nil {
+				continue
+			}
+			var ok bool
+			if items, ok = value.([]interface{}); !ok {
+				return nil, false
+			}
+		case strings.EqualFold(key, "kind"), strings.EqualFold(key, "version"):
+			if _, ok := value.(string); !ok && value != nil {
+				return nil, false
+			}
+		}
+	}
+	return items, items != nil
+}
+
 // validateResource validates a single Kubernetes resource against
 // the relevant schema, detecting the type of resource automatically.
 // Returns the result and raw YAML body as map.
-func validateResource(data []byte, schemaCache map[string]*gojsonschema.Schema, config *Config) (ValidationResult, map[string]interface{}, error) {
+func validateResource(doc document, schemaCache map[string]*gojsonschema.Schema, config *Config) (ValidationResult, map[string]interface{}, error) {
 	result := ValidationResult{}
 	result.FileName = config.FileName
-	var body map[string]interface{}
-	err := yaml.Unmarshal(data, &body)
-	if err != nil {
-		return result, body, fmt.Errorf("Failed to decode YAML from %s: %s", result.FileName, err.Error())
+	body := doc.body
+	if doc.err != nil {
+		return result, body, fmt.Errorf("Failed to decode YAML from %s: %s", result.FileName, doc.err.Error())
 	} else if body == nil {
 		return result, body, nil
 	}
 
-	metadata, _ := getObject(body, "metadata")
-	if metadata != nil {
-		namespace, _ := getString(metadata, "namespace")
-		name, _ := getString(metadata, "name")
-		generateName, _ := getString(metadata, "generateName")
-
-		if len(name) == 0 && len(generateName) > 0 {
-			result.ResourceName = fmt.Sprintf("%s{{ generateName }}", generateName)
-		} else {
-			result.ResourceName = name
-		}
-		result.ResourceNamespace = namespace
-	}
+	result.ResourceName, result.ResourceNamespace = metadataName(body)
 
 	kind, err := getString(body, "kind")
 	if err != nil {
@@ -156,11 +253,14 @@ func validateResource(data []byte, schemaCache map[string]*gojsonschema.Schema,
 		return result, body, fmt.Errorf("Prohibited resource kind '%s' in %s", kind, result.FileName)
 	}
 
-	schemaErrors, err := validateAgainstSchema(body, &result, schemaCache, config)
+	schemaBody, statusFindings := stripStatus(body, config)
+	schemaErrors, err := validateAgainstSchema(schemaBody, &result, schemaCache, config)
 	if err != nil {
 		return result, body, fmt.Errorf("%s: %s", result.FileName, err.Error())
 	}
-	result.Errors = schemaErrors
+	result.Errors, result.Findings = applyErrorSeverities(filterStatusErrors(schemaErrors, config), config)
+	result.Errors = positionErrors(result.Errors, doc)
+	result.Findings = append(statusFindings, result.Findings...)
 	return result, body, nil
 }
 
@@ -171,13 +271,6 @@ func validateAgainstSchema(body interface{}, resource *ValidationResult, schemaC
 		return handleMissingSchema(err, config)
 	}
 
-	// Without forcing these types the schema fails to load
-	// Need to Work out proper handling for these types
-	gojsonschema.FormatCheckers.Add("int64", ValidFormat{})
-	gojsonschema.FormatCheckers.Add("byte", ValidFormat{})
-	gojsonschema.FormatCheckers.Add("int32", ValidFormat{})
-	gojsonschema.FormatCheckers.Add("int-or-string", ValidFormat{})
-
 	documentLoader := gojsonschema.NewGoLoader(body)
 	results, err := schema.Validate(documentLoader)
 	if err != nil {
@@ -187,7 +280,7 @@ func validateAgainstSchema(body interface{}, resource *ValidationResult, schemaC
 	}
 	resource.ValidatedAgainstSchema = true
 	if !results.Valid() {
-		return results.Errors(), nil
+		return redactErrors(results.Errors(), resource.Kind, config), nil
 	}
 
 	return []gojsonschema.ResultError{}, nil
@@ -201,13 +294,24 @@ func downloadSchema(resource *ValidationResult, schemaCache map[string]*gojsonsc
 	}
 
 	// We haven't cached this schema yet; look for one that works
-	primarySchemaBaseURL := determineSchemaBaseURL(config)
-	primarySchemaRef := determineSchemaURL(primarySchemaBaseURL, resource.Kind, resource.APIVersion, config)
-	schemaRefs := []string{primarySchemaRef}
+	var schemaRefs []string
+	if config.schemaIndex != nil {
+		// An index replaces constructing URLs by convention entirely
+		schemaRef, err := config.schemaIndex.lookup(resource)
+		if err != nil {
+			schemaCache[resource.VersionKind()] = nil
+			return nil, err
+		}
+		schemaRefs = []string{schemaRef}
+	} else {
+		primarySchemaBaseURL := determineSchemaBaseURL(config)
+		primarySchemaRef := determineSchemaURL(primarySchemaBaseURL, resource.Kind, resource.APIVersion, config)
+		schemaRefs = []string{primarySchemaRef}
 
-	for _, additionalSchemaURLs := range config.AdditionalSchemaLocations {
-		additionalSchemaRef := determineSchemaURL(additionalSchemaURLs, resource.Kind, resource.APIVersion, config)
-		schemaRefs = append(schemaRefs, additionalSchemaRef)
+		for _, additionalSchemaURLs := range config.AdditionalSchemaLocations {
+			additionalSchemaRef := determineSchemaURL(additionalSchemaURLs, resource.Kind, resource.APIVersion, config)
+			schemaRefs = append(schemaRefs, additionalSchemaRef)
+		}
 	}
 
 	var errors *multierror.Error
@@ -264,45 +368,152 @@ func ValidateWithCache(input []byte, schemaCache map[string]*gojsonschema.Schema
 		config = conf[0]
 	}
 
-	results := make([]ValidationResult, 0)
+	results, set, err := validateInput(input, schemaCache, config)
+	runChecks(results, set, config)
+	return results, err
+}
+
+// A ManifestSet validates several inputs, such as the files of a directory,
+// as a single set of manifests, so that the optional checks which span
+// documents see the resources of every input rather than only those of the
+// same input.
+type ManifestSet struct {
+	schemaCache map[string]*gojsonschema.Schema
+	config      *Config
+
+	results []ValidationResult
+	set     []resource
+}
+
+// NewManifestSet returns an empty ManifestSet which validates inputs
+// according to config, caching schemas in schemaCache. It returns an error
+// if config can't be used to validate any input, such as when the schema
+// index can't be loaded, so that it is reported once rather than per input
+func NewManifestSet(schemaCache map[string]*gojsonschema.Schema, config *Config) (*ManifestSet, error) {
+	if err := validateConfig(config); err != nil {
+		return nil, err
+	}
+	return &ManifestSet{
+		schemaCache: schemaCache,
+		config:      config,
+	}, nil
+}
+
+// Validate validates one input of the set. Without any optional checks its
+// results are returned straight away. Otherwise they are held until Check is
+// called, so that the checks can see every input. An input which fails to
+// validate is checked on its own, and its results returned with the error.
+func (m *ManifestSet) Validate(input []byte) ([]ValidationResult, error) {
+	results, set, err := validateInput(input, m.schemaCache, m.config)
+	if err != nil || len(enabledChecks(m.config)) == 0 {
+		runChecks(results, set, m.config)
+		return results, err
+	}
+
+	for _, r := range set {
+		r.index += len(m.results)
+		m.set = append(m.set, r)
+	}
+	m.results = append(m.results, results...)
+	return nil, nil
+}
+
+// Check runs the optional checks across the resources of every input held
+// by the set, and returns their results in the order they were validated
+func (m *ManifestSet) Check() []ValidationResult {
+	runChecks(m.results, m.set, m.config)
+	results := m.results
+	m.results, m.set = nil, nil
+	return results
+}
 
+// validateConfig returns an error if config can't be used to validate any
+// input, loading the schema index if one is set
+func validateConfig(config *Config) error {
 	if len(config.DefaultNamespace) == 0 {
-		return results, fmt.Errorf("Default namespace ('-n/--default-namespace' flag) must not be empty")
+		return fmt.Errorf("Default namespace ('-n/--default-namespace' flag) must not be empty")
+	}
+
+	if err := validateCheckIDs(config); err != nil {
+		return err
+	}
+
+	if err := validateErrorSeverities(config); err != nil {
+		return err
+	}
+
+	if err := validateStrictStatus(config); err != nil {
+		return err
+	}
+
+	return loadSchemaIndex(config)
+}
+
+// validateInput validates every document of input, returning their results
+// along with the resources which decoded successfully, for the optional
+// checks
+func validateInput(input []byte, schemaCache map[string]*gojsonschema.Schema, config *Config) ([]ValidationResult, []resource, error) {
+	results := make([]ValidationResult, 0)
+
+	if err := validateConfig(config); err != nil {
+		return results, nil, err
 	}
 
 	if len(input) == 0 {
 		result := ValidationResult{}
 		result.FileName = config.FileName
 		results = append(results, result)
-		return results, nil
+		return results, nil, nil
 	}
 
-	splitBits := bytes.Split(input, []byte(detectLineBreak(input)+"---"+detectLineBreak(input)))
-	bits := make([][]byte, len(splitBits))
+	var splitBits [][]byte
+	switch config.InputFormat {
+	case "", InputFormatYAML:
+		splitBits = bytes.Split(input, []byte(detectLineBreak(input)+"---"+detectLineBreak(input)))
+	case InputFormatJSON:
+		// JSON input is always a single document, which may be a List
+		if !json.Valid(input) {
+			return results, nil, fmt.Errorf("Failed to decode JSON from %s", config.FileName)
+		}
+		splitBits = [][]byte{input}
+	default:
+		return results, nil, fmt.Errorf("Unknown input format '%s', valid formats are: %s, %s", config.InputFormat, InputFormatYAML, InputFormatJSON)
+	}
+	bits := make([]document, len(splitBits))
 	j := 0
 
 	// split any list into its elements and add them to "bits"
+	line := 1
 	for _, element := range splitBits {
-
-		list := struct {
-			Version string
-			Kind    string
-			Items   []interface{}
-		}{}
-
-		unmarshalErr := yaml.Unmarshal(element, &list)
-		isYamlList := unmarshalErr == nil && list.Items != nil
+		doc := decodeDocument(element)
+		doc.src = &source{data: element, line: line}
+		// the separator takes up the line break ending this document and
+		// the line of the separator itself
+		line += bytes.Count(element, []byte("\n")) + 2
+		items, isYamlList := listItems(doc)
 
 		if isYamlList {
-			listBits := make([][]byte, len(list.Items))
-			for i, item := range list.Items {
-				b, _ := yaml.Marshal(item)
-				listBits[i] = b
+			itemsKey := "items"
+			for key := range doc.body {
+				if strings.EqualFold(key, "items") {
+					itemsKey = key
+				}
+			}
+			listBits := make([]document, len(items))
+			for i, item := range items {
+				if body, ok := item.(map[string]interface{}); ok {
+					listBits[i] = document{body: body}
+				} else {
+					b, _ := yaml.Marshal(item)
+					listBits[i] = decodeDocument(b)
+				}
+				listBits[i].src = doc.src
+				listBits[i].path = []string{itemsKey, strconv.Itoa(i)}
 			}
 			bits = append(bits, listBits...)
-			j += len(list.Items)
+			j += len(items)
 		} else {
-			bits[j] = element
+			bits[j] = doc
 			j++
 		}
 	}
@@ -310,7 +521,7 @@ func ValidateWithCache(input []byte, schemaCache map[string]*gojsonschema.Schema
 	var errors *multierror.Error
 
 	// special case regexp for helm
-	helmSourcePattern := regexp.MustCompile(`^(?:---` + detectLineBreak(input) + `)?# Source: (.*)`)
+	helmSourcePattern := helmSourcePatterns[detectLineBreak(input)]
 
 	// Save the fileName we were provided; if we detect a new fileName
 	// we'll use that, but we'll need to revert to the default afterward
@@ -322,20 +533,28 @@ func ValidateWithCache(input []byte, schemaCache map[string]*gojsonschema.Schema
 
 	seenResourcesSet := make(map[[4]string]bool) // set of [API version, kind, namespace, name]
 
+	// resources which decoded successfully, for the optional checks
+	var set []resource
+
 	for _, element := range bits {
-		if len(element) > 0 {
-			if found := helmSourcePattern.FindStringSubmatch(string(element)); found != nil {
-				config.FileName = found[1]
+		if !element.empty() {
+			if found := helmSourcePattern.FindSubmatch(element.data); found != nil {
+				config.FileName = string(found[1])
+				// positions in the rendered output don't match the template
+				element.src = nil
 			}
 
 			result, body, err := validateResource(element, schemaCache, config)
 			if err != nil {
 				errors = multierror.Append(errors, err)
 				if config.ExitOnError {
-					return results, errors
+					return results, set, errors
 				}
 			} else {
 				if !in(config.KindsToSkip, result.Kind) {
+					if body != nil {
+						set = append(set, resource{index: len(results), body: body})
+					}
 
 					metadata, _ := getObject(body, "metadata")
 					if metadata != nil {
@@ -372,7 +591,7 @@ func ValidateWithCache(input []byte, schemaCache map[string]*gojsonschema.Schema
 	if errors != nil {
 		errors.ErrorFormat = singleLineErrorFormat
 	}
-	return results, errors.ErrorOrNil()
+	return results, set, errors.ErrorOrNil()
 }
 
 func singleLineErrorFormat(es []error) string {
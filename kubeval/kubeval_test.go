@@ -1,9 +1,14 @@
 package kubeval
 
 import (
+	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
 	multierror "github.com/hashicorp/go-multierror"
@@ -273,6 +278,20 @@ func TestDetermineSchemaURL(t *testing.T) {
 			version:  "extensions/v1beta1",
 			expected: "https://base/master-standalone/sample-extensions-v1beta1.json",
 		},
+		{
+			config:   &Config{KubernetesVersion: "prerelease"},
+			baseURL:  "https://base",
+			kind:     "sample",
+			version:  "v1",
+			expected: "https://base/master-standalone/sample-v1.json",
+		},
+		{
+			config:   &Config{KubernetesVersion: "1.22.0-rc.0"},
+			baseURL:  "https://base",
+			kind:     "sample",
+			version:  "v1",
+			expected: "https://base/master-standalone/sample-v1.json",
+		},
 		{
 			config:   &Config{KubernetesVersion: "master", OpenShift: true},
 			baseURL:  "https://base",
@@ -457,3 +476,617 @@ func TestFlagAdding(t *testing.T) {
 		}
 	}
 }
+
+func TestRedactErrors(t *testing.T) {
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewStringLoader(`{
+		"properties": {
+			"type": {"enum": ["Opaque", "kubernetes.io/tls"]},
+			"replicas": {"type": "integer", "minimum": 1},
+			"data": {"properties": {"password": {"type": "string", "pattern": "^[a-z]+$"}}}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load test schema: %v", err)
+	}
+
+	var tests = []struct {
+		kind     string
+		document string
+		redacted bool
+	}{
+		{
+			kind:     "Secret",
+			document: `{"type": "Always"}`,
+			redacted: true,
+		},
+		{
+			kind:     "Secret",
+			document: `{"replicas": 0}`,
+			redacted: true,
+		},
+		{
+			kind:     "ConfigMap",
+			document: `{"data": {"password": "hunter2"}}`,
+			redacted: true,
+		},
+		{
+			kind:     "ConfigMap",
+			document: `{"replicas": 0}`,
+			redacted: false,
+		},
+		{
+			kind:     "Service",
+			document: `{"type": "Always"}`,
+			redacted: false,
+		},
+	}
+
+	config := NewDefaultConfig()
+	config.RedactFields = []string{"data"}
+	for _, test := range tests {
+		results, err := schema.Validate(gojsonschema.NewStringLoader(test.document))
+		if err != nil {
+			t.Fatalf("Failed to validate %s: %v", test.document, err)
+		}
+		original := results.Errors()
+		if len(original) != 1 {
+			t.Fatalf("Expected 1 error for %s, got %v", test.document, original)
+		}
+		message, value := original[0].String(), original[0].Value()
+
+		errs := redactErrors(results.Errors(), test.kind, config)
+		if errs[0].String() != message {
+			t.Errorf("Expected the message for %s to be unchanged as %s, got %s", test.document, message, errs[0].String())
+		}
+		if test.redacted {
+			value = redactedValue
+		}
+		if errs[0].Value() != value {
+			t.Errorf("Expected the value for %s to be %v, got %v", test.document, value, errs[0].Value())
+		}
+	}
+}
+
+func TestRedactFindings(t *testing.T) {
+	job := `
+kind: Job
+spec:
+  template:
+    spec:
+      containers:
+      - name: Not_A_Label
+`
+	var tests = []struct {
+		configure func(config *Config)
+		redacted  bool
+	}{
+		{
+			configure: func(config *Config) {},
+		},
+		{
+			configure: func(config *Config) { config.RedactFields = []string{"spec.template.spec.containers"} },
+			redacted:  true,
+		},
+		{
+			configure: func(config *Config) { config.RedactKinds = []string{"Job"} },
+			redacted:  true,
+		},
+	}
+
+	for _, test := range tests {
+		config := NewDefaultConfig()
+		test.configure(config)
+		f := runCheck(t, config, "container-names", job)[0][0]
+		if f.Path != "spec.template.spec.containers.0.name" {
+			t.Errorf("Expected the path to be kept, got %s", f.Path)
+		}
+		if redacted := !strings.Contains(f.Message, "Not_A_Label"); redacted != test.redacted {
+			t.Errorf("Expected redacted to be %t, got message %s", test.redacted, f.Message)
+		}
+	}
+}
+
+func TestSchemaIndexErrorCached(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := NewDefaultConfig()
+	config.SchemaIndex = filepath.Join(dir, "index.yaml")
+	if _, err := NewManifestSet(NewSchemaCache(), config); err == nil {
+		t.Fatal("Expected an error for a missing schema index, but didn't receive one")
+	}
+
+	// the index isn't read again once it has failed to load
+	if err := ioutil.WriteFile(config.SchemaIndex, []byte("v1/Secret: secret.json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config.FileName = "secret.yaml"
+	if _, err := Validate([]byte("apiVersion: v1\nkind: Secret\n"), config); err == nil {
+		t.Error("Expected the cached error, but didn't receive one")
+	}
+}
+
+func TestManifestSet(t *testing.T) {
+	pod := []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  imagePullSecrets:
+  - name: registry
+  - name: missing
+`)
+	secret := []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: registry
+`)
+
+	config := NewDefaultConfig()
+	config.SchemaIndex = "../fixtures/schema-index/index.yaml"
+	config.IgnoreMissingSchemas = true
+	config.Checks = []string{"image-pull-secrets"}
+
+	// validated on its own, the pod's file has no Secrets to compare against
+	config.FileName = "pod.yaml"
+	results, err := Validate(pod, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if len(results[0].Findings) != 0 {
+		t.Errorf("Expected no findings for a single file, got %v", results[0].Findings)
+	}
+
+	// in a set, the checks see the Secrets of every file
+	set, err := NewManifestSet(NewSchemaCache(), config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	for _, input := range []struct {
+		fileName string
+		body     []byte
+	}{{"pod.yaml", pod}, {"secret.yaml", secret}} {
+		config.FileName = input.fileName
+		results, err := set.Validate(input.body)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if len(results) != 0 {
+			t.Errorf("Expected results to be held until Check, got %v", results)
+		}
+	}
+	results = set.Check()
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for _, r := range results {
+		expected := 0
+		if r.Kind == "Pod" {
+			expected = 1
+		}
+		if len(r.Findings) != expected {
+			t.Errorf("Expected %d findings for %s, got %v", expected, r.FileName, r.Findings)
+		}
+	}
+
+	// without checks, results are returned as each input is validated
+	config.Checks = []string{}
+	set, _ = NewManifestSet(NewSchemaCache(), config)
+	if results, _ := set.Validate(secret); len(results) != 1 {
+		t.Errorf("Expected 1 result, got %d", len(results))
+	}
+	if results := set.Check(); len(results) != 0 {
+		t.Errorf("Expected no held results, got %d", len(results))
+	}
+}
+
+func TestSchemaIndex(t *testing.T) {
+	var tests = []struct {
+		index     string
+		fixture   string
+		configure func(config *Config)
+		expectErr bool
+		errors    int
+	}{
+		{
+			index:   "../fixtures/schema-index/index.yaml",
+			fixture: "valid.yaml",
+		},
+		{
+			index:   "../fixtures/schema-index/index.yaml",
+			fixture: "invalid.yaml",
+			errors:  1,
+		},
+		{
+			index:     "../fixtures/schema-index/index.yaml",
+			fixture:   "valid.json",
+			expectErr: true,
+		},
+		{
+			index:     "../fixtures/schema-index/malformed.yaml",
+			fixture:   "valid.yaml",
+			expectErr: true,
+		},
+		{
+			index:     "../fixtures/schema-index/missing.yaml",
+			fixture:   "valid.yaml",
+			expectErr: true,
+		},
+		{
+			index:     "../fixtures/schema-index/index.yaml",
+			fixture:   "valid.yaml",
+			configure: func(config *Config) { config.Strict = true },
+			expectErr: true,
+		},
+		{
+			index:     "../fixtures/schema-index/index.yaml",
+			fixture:   "valid.yaml",
+			configure: func(config *Config) { config.OpenShift = true },
+			expectErr: true,
+		},
+		{
+			index:     "../fixtures/schema-index/index.yaml",
+			fixture:   "valid.yaml",
+			configure: func(config *Config) { config.KubernetesVersion = "1.18.0" },
+			expectErr: true,
+		},
+	}
+	for i, test := range tests {
+		filePath, _ := filepath.Abs("../fixtures/" + test.fixture)
+		fileContents, _ := ioutil.ReadFile(filePath)
+		config := NewDefaultConfig()
+		config.FileName = test.fixture
+		config.SchemaIndex = test.index
+		if test.configure != nil {
+			test.configure(config)
+		}
+		results, err := Validate(fileContents, config)
+		if test.expectErr {
+			if err == nil {
+				t.Errorf("test #%d: Expected an error, but didn't receive one", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test #%d: Unexpected error: %s", i, err.Error())
+		} else if len(results[0].Errors) != test.errors {
+			t.Errorf("test #%d: Expected %d errors, got %v", i, test.errors, results[0].Errors)
+		}
+	}
+}
+
+func TestQualifyName(t *testing.T) {
+	var tests = []struct {
+		obj      map[string]interface{}
+		expected string
+	}{
+		{
+			obj:      map[string]interface{}{},
+			expected: "unknown",
+		},
+		{
+			obj:      map[string]interface{}{"metadata": map[string]interface{}{"name": "bob"}},
+			expected: "bob",
+		},
+		{
+			obj:      map[string]interface{}{"metadata": map[string]interface{}{"name": "bob", "namespace": "team"}},
+			expected: "team.bob",
+		},
+		{
+			obj:      map[string]interface{}{"metadata": map[string]interface{}{"generateName": "pi-"}},
+			expected: "pi-{{ generateName }}",
+		},
+	}
+	for _, test := range tests {
+		actual := QualifyName(test.obj)
+		if actual != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, actual)
+		}
+	}
+}
+
+func TestValidateInputFormat(t *testing.T) {
+	var tests = []struct {
+		format    string
+		fixture   string
+		expectErr bool
+	}{
+		{
+			format:  InputFormatYAML,
+			fixture: "valid.yaml",
+		},
+		{
+			format:  InputFormatJSON,
+			fixture: "valid.json",
+		},
+		{
+			format:    InputFormatJSON,
+			fixture:   "valid.yaml",
+			expectErr: true,
+		},
+		{
+			format:    "toml",
+			fixture:   "valid.yaml",
+			expectErr: true,
+		},
+	}
+	for i, test := range tests {
+		filePath, _ := filepath.Abs("../fixtures/" + test.fixture)
+		fileContents, _ := ioutil.ReadFile(filePath)
+		config := NewDefaultConfig()
+		config.FileName = test.fixture
+		config.InputFormat = test.format
+		config.KindsToSkip = []string{"ReplicationController", "Deployment"}
+		_, err := Validate(fileContents, config)
+		if test.expectErr && err == nil {
+			t.Errorf("test #%d: Expected an error, but didn't receive one", i)
+		} else if !test.expectErr && err != nil {
+			t.Errorf("test #%d: Unexpected error: %s", i, err.Error())
+		}
+	}
+}
+
+func TestApplyErrorSeverities(t *testing.T) {
+	newError := func(errorType string) gojsonschema.ResultError {
+		r := &gojsonschema.ResultErrorFields{}
+		r.SetContext(gojsonschema.NewJsonContext("spec", gojsonschema.NewJsonContext("(root)", nil)))
+		r.SetType(errorType)
+		r.SetDescription(errorType)
+		return r
+	}
+	errs := []gojsonschema.ResultError{
+		newError("required"),
+		newError("additional_property_not_allowed"),
+		newError("enum"),
+	}
+
+	config := NewDefaultConfig()
+	config.ErrorSeverities = map[string]string{
+		"additional_property_not_allowed": "warning",
+		"enum":                            "error",
+	}
+	if err := validateErrorSeverities(config); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	remaining, findings := applyErrorSeverities(errs, config)
+	if len(remaining) != 2 || remaining[0].Type() != "required" || remaining[1].Type() != "enum" {
+		t.Errorf("Expected required and enum errors to remain, got %v", remaining)
+	}
+	if len(findings) != 1 || findings[0].Severity != SeverityWarning || findings[0].CheckID != "additional_property_not_allowed" || findings[0].Path != "spec" {
+		t.Errorf("Expected a single warning for additional_property_not_allowed, got %v", findings)
+	}
+
+	config.ErrorSeverities = map[string]string{"not_an_error_type": "warning"}
+	if err := validateErrorSeverities(config); err == nil {
+		t.Errorf("Expected an error for an unknown error type")
+	}
+	config.ErrorSeverities = map[string]string{"required": "fatal"}
+	if err := validateErrorSeverities(config); err == nil {
+		t.Errorf("Expected an error for an unknown severity")
+	}
+}
+
+func TestIsQuantity(t *testing.T) {
+	var tests = []struct {
+		value    interface{}
+		expected bool
+	}{
+		{value: "128Mi", expected: true},
+		{value: "1.5Gi", expected: true},
+		{value: "250m", expected: true},
+		{value: "1e3", expected: true},
+		{value: ".5", expected: true},
+		{value: float64(2), expected: true},
+		{value: "", expected: false},
+		{value: "128MB", expected: false},
+		{value: "lots", expected: false},
+		{value: true, expected: false},
+	}
+	for _, test := range tests {
+		if actual := isQuantity(test.value); actual != test.expected {
+			t.Errorf("isQuantity(%v) should be %t, got %t", test.value, test.expected, actual)
+		}
+	}
+}
+
+// BenchmarkValidateWithCache measures the common case of validating many
+// files against a single Kubernetes version, sharing one schema cache. It
+// uses a schema index so that no schemas are fetched over the network.
+func BenchmarkValidateWithCache(b *testing.B) {
+	document, err := ioutil.ReadFile("../fixtures/valid.yaml")
+	if err != nil {
+		b.Fatal(err)
+	}
+	documents := make([][]byte, 50)
+	for i := range documents {
+		documents[i] = bytes.Replace(document, []byte(`name: "bob"`), []byte(fmt.Sprintf("name: bob-%d", i)), 1)
+	}
+	input := bytes.Join(documents, []byte("---\n"))
+
+	config := NewDefaultConfig()
+	config.SchemaIndex = "../fixtures/schema-index/index.yaml"
+	schemaCache := NewSchemaCache()
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		config.FileName = fmt.Sprintf("manifests/%d.yaml", i)
+		if _, err := ValidateWithCache(input, schemaCache, config); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestListItems(t *testing.T) {
+	var tests = []struct {
+		Name     string
+		Input    string
+		Expected int
+		IsList   bool
+	}{
+		{"list", "kind: List\nitems:\n- kind: Pod\n- kind: Service\n", 2, true},
+		{"empty list", "kind: List\nitems: []\n", 0, true},
+		{"capitalised items", "Items:\n- kind: Pod\n", 1, true},
+		{"null items", "kind: List\nitems:\n", 0, false},
+		{"items not a list", "kind: ConfigMap\nitems: foo\n", 0, false},
+		{"kind not a string", "kind: 1\nitems:\n- kind: Pod\n", 0, false},
+		{"not a list", "kind: Pod\n", 0, false},
+		{"invalid", "- kind: Pod\n", 0, false},
+	}
+	for _, test := range tests {
+		items, isList := listItems(decodeDocument([]byte(test.Input)))
+		if isList != test.IsList || len(items) != test.Expected {
+			t.Errorf("%s: expected list %t with %d items, got list %t with %d items", test.Name, test.IsList, test.Expected, isList, len(items))
+		}
+	}
+}
+
+func TestStrictStatus(t *testing.T) {
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"apiVersion": {"type": "string"},
+			"kind": {"type": "string"},
+			"metadata": {"type": "object"},
+			"status": {
+				"type": "object",
+				"additionalProperties": false,
+				"properties": {"phase": {"type": "string"}}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+status:
+  phase: 1
+  podIP: 10.0.0.1
+`)
+
+	var tests = []struct {
+		StrictStatus string
+		Errors       []string
+		Findings     int
+	}{
+		{StrictStatusValidate, []string{"additional_property_not_allowed", "invalid_type"}, 0},
+		{StrictStatusLenient, []string{"invalid_type"}, 0},
+		{StrictStatusStrip, []string{}, 1},
+	}
+	for _, test := range tests {
+		config := NewDefaultConfig()
+		config.Strict = true
+		config.StrictStatus = test.StrictStatus
+		schemaCache := NewSchemaCache()
+		schemaCache["v1/Pod"] = schema
+		results, err := ValidateWithCache(input, schemaCache, config)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", test.StrictStatus, err.Error())
+		}
+		errorTypes := []string{}
+		for _, e := range results[0].Errors {
+			errorTypes = append(errorTypes, e.Type())
+		}
+		sort.Strings(errorTypes)
+		if !reflect.DeepEqual(errorTypes, test.Errors) || len(results[0].Findings) != test.Findings {
+			t.Errorf("%s: expected errors %v and %d findings, got %v and %v", test.StrictStatus, test.Errors, test.Findings, errorTypes, results[0].Findings)
+		}
+	}
+
+	config := NewDefaultConfig()
+	config.StrictStatus = "ignore"
+	if _, err := Validate(input, config); err == nil {
+		t.Errorf("Expected an error for unknown strict status handling")
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"additionalProperties": false,
+		"required": ["spec"],
+		"properties": {
+			"apiVersion": {"type": "string"},
+			"kind": {"type": "string"},
+			"metadata": {"type": "object"},
+			"spec": {
+				"type": "object",
+				"properties": {
+					"replicas": {"type": "integer"},
+					"ports": {"type": "array", "items": {"type": "integer"}}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: a
+  annotations:
+    app.kubernetes.io/name: a
+spec:
+  replicas: two
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: b
+  spec:
+    ports: [80, http]
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: c
+  extra: true
+`)
+
+	config := NewDefaultConfig()
+	schemaCache := NewSchemaCache()
+	schemaCache["v1/Pod"] = schema
+	results, err := ValidateWithCache(input, schemaCache, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	var tests = []struct {
+		Result int
+		Type   string
+		Line   int
+		Column int
+	}{
+		{0, "invalid_type", 8, 3},
+		{2, "invalid_type", 18, 17},
+		// the missing spec is reported on the resource itself
+		{3, "required", 19, 3},
+		{3, "additional_property_not_allowed", 23, 3},
+	}
+	for _, test := range tests {
+		found := false
+		for _, e := range results[test.Result].Errors {
+			if e.Type() != test.Type {
+				continue
+			}
+			found = true
+			line, column, ok := errorPosition(e)
+			if !ok || line != test.Line || column != test.Column {
+				t.Errorf("%d %s: expected position %d:%d, got %d:%d", test.Result, test.Type, test.Line, test.Column, line, column)
+			}
+		}
+		if !found {
+			t.Errorf("%d: expected a %s error, got %v", test.Result, test.Type, results[test.Result].Errors)
+		}
+	}
+}
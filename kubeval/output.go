@@ -2,12 +2,25 @@ package kubeval
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
 
 	kLog "github.com/instrumenta/kubeval/log"
+	"github.com/xeipuuv/gojsonschema"
 )
 
 // TODO (brendanryan) move these structs to `/log` once we have removed the potential
@@ -16,16 +29,44 @@ import (
 // outputManager controls how results of the `kubeval` evaluation will be recorded
 // and reported to the end user.
 // This interface is kept private to ensure all implementations are closed within
-// this package.
+// this package. Implementations are safe for Put to be called from several
+// goroutines at once.
 type outputManager interface {
 	Put(r ValidationResult) error
 	Flush() error
+	// HasErrors returns whether any of the results put were invalid, or had
+	// findings with error severity, including results left out of the output
+	HasErrors() bool
+}
+
+// invalidCount counts the failing results put into an output manager, and
+// is embedded in output managers to implement HasErrors
+type invalidCount struct {
+	invalid int32
+}
+
+// record counts r if it fails validation
+func (c *invalidCount) record(r ValidationResult) {
+	if hasErrors(r) {
+		atomic.AddInt32(&c.invalid, 1)
+	}
+}
+
+// HasErrors returns whether any failing results were recorded
+func (c *invalidCount) HasErrors() bool {
+	return atomic.LoadInt32(&c.invalid) > 0
 }
 
 const (
-	outputSTD  = "stdout"
-	outputJSON = "json"
-	outputTAP  = "tap"
+	outputSTD      = "stdout"
+	outputJSON     = "json"
+	outputTAP      = "tap"
+	outputJUnit    = "junit"
+	outputGithub   = "github"
+	outputJSONL    = "jsonl"
+	outputTemplate = "template"
+	outputCSV      = "csv"
+	outputMarkdown = "markdown"
 )
 
 func validOutputs() []string {
@@ -33,52 +74,400 @@ func validOutputs() []string {
 		outputSTD,
 		outputJSON,
 		outputTAP,
+		outputJUnit,
+		outputGithub,
+		outputJSONL,
+		outputTemplate,
+		outputCSV,
+		outputMarkdown,
 	}
 }
 
-func GetOutputManager(outFmt string, failuresOnly bool) outputManager {
+// GetOutputManager returns the output manager for outFmt, which writes its
+// output to stdout. An empty outFmt selects the stdout format
+func GetOutputManager(outFmt string, failuresOnly, skipWarnings bool) (outputManager, error) {
+	return GetOutputManagerWithWriter(outFmt, os.Stdout, failuresOnly, skipWarnings)
+}
+
+// GetOutputManagerWithWriter returns the output manager for outFmt, which
+// writes its output to w rather than to stdout
+func GetOutputManagerWithWriter(outFmt string, w io.Writer, failuresOnly, skipWarnings bool) (outputManager, error) {
 	switch outFmt {
-	case outputSTD:
-		return newSTDOutputManager(failuresOnly)
+	case "", outputSTD:
+		return newSTDOutputManager(w, failuresOnly, skipWarnings), nil
 	case outputJSON:
-		return newDefaultJSONOutputManager(failuresOnly)
+		return newJSONOutputManager(log.New(w, "", 0), failuresOnly, skipWarnings), nil
 	case outputTAP:
-		return newDefaultTAPOutputManager(failuresOnly)
+		return newTAPOutputManager(log.New(w, "", 0), failuresOnly, skipWarnings), nil
+	case outputJUnit:
+		return newJUnitOutputManager(log.New(w, "", 0), failuresOnly, skipWarnings), nil
+	case outputGithub:
+		return newGithubOutputManager(log.New(w, "", 0), skipWarnings), nil
+	case outputJSONL:
+		return newJSONLOutputManager(log.New(w, "", 0), failuresOnly, skipWarnings), nil
+	case outputCSV:
+		return newCSVOutputManager(w, failuresOnly, skipWarnings), nil
+	case outputMarkdown:
+		return newMarkdownOutputManager(log.New(w, "", 0), failuresOnly, skipWarnings), nil
+	case outputTemplate:
+		return nil, fmt.Errorf("--output %s requires a template, use GetOutputManagerWithTemplate", outputTemplate)
 	default:
-		return newSTDOutputManager(failuresOnly)
+		return nil, fmt.Errorf("Unknown output format '%s', valid formats are: %s", outFmt, strings.Join(validOutputs(), ", "))
+	}
+}
+
+// GetOutputManagerWithTemplate returns an output manager which writes each
+// result to w using the text/template tmpl. The template is executed
+// against a TemplateResult, and returns an error if it cannot be parsed
+func GetOutputManagerWithTemplate(tmpl string, w io.Writer, failuresOnly, skipWarnings bool) (outputManager, error) {
+	t, err := newTemplateOutputManager(log.New(w, "", 0), tmpl, failuresOnly, skipWarnings)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetOutputManagerFromConfig returns the output manager for the formats
+// selected in config, with the console output written to stdout. When
+// config.OutputDir is set, the results for each input file are written to a
+// file in that directory instead of the console. When config.OutputJSONFile
+// is set, results are also written to that file as JSON, in addition to the
+// console output.
+func GetOutputManagerFromConfig(config *Config) (outputManager, error) {
+	return GetOutputManagerFromConfigWithWriter(config, os.Stdout)
+}
+
+// GetOutputManagerFromConfigWithWriter returns the output manager for the
+// formats selected in config, which writes the console output to w rather
+// than to stdout
+func GetOutputManagerFromConfigWithWriter(config *Config, w io.Writer) (outputManager, error) {
+	if err := validateOutputFlags(config); err != nil {
+		return nil, err
+	}
+
+	var run *runMetadata
+	if config.RunMetadata || config.RunID != "" {
+		var err error
+		run, err = newRunMetadata(config.RunID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	color, err := UseColor(config.Color, w)
+	if err != nil {
+		return nil, err
+	}
+
+	var console outputManager
+	if config.OutputDir != "" {
+		dir, err := newDirOutputManager(config.OutputDir, config.OutputFormat, config.FailuresOnly, config.SkipWarnings, run)
+		if err != nil {
+			return nil, err
+		}
+		console = dir
+	} else if config.OutputFormat == outputTemplate {
+		if config.OutputTemplate == "" {
+			return nil, fmt.Errorf("--output %s requires a template to be passed with --template", outputTemplate)
+		}
+		var err error
+		console, err = GetOutputManagerWithTemplate(config.OutputTemplate, w, config.FailuresOnly, config.SkipWarnings)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		console, err = GetOutputManagerWithWriter(config.OutputFormat, w, config.FailuresOnly, config.SkipWarnings)
+		if err != nil {
+			return nil, err
+		}
+		applyOutputFlags(console, config, run, color)
+	}
+	if config.OutputJSONFile == "" {
+		return console, nil
+	}
+	file, err := newFileOutputManager(outputJSON, config.OutputJSONFile, config.FailuresOnly, config.SkipWarnings, run)
+	if err != nil {
+		return nil, err
+	}
+	applyOutputFlags(file.outputManager, config, run, color)
+	return newMultiOutputManager(console, file), nil
+}
+
+// applyOutputFlags passes the output flags in config on to m, for those of
+// them which its format uses. validateOutputFlags has already rejected any
+// which none of the selected formats can honour
+func applyOutputFlags(m outputManager, config *Config, run *runMetadata, color bool) {
+	switch c := m.(type) {
+	case *STDOutputManager:
+		c.GroupByFile = config.GroupByFile
+		c.Color = color
+		c.ByNamespace = config.SummaryByNamespace
+		c.summary = newSummary(config.DefaultNamespace)
+	case *jsonOutputManager:
+		c.run = run
+		if config.SummaryByNamespace {
+			c.summary = newSummary(config.DefaultNamespace)
+		}
+	case *junitOutputManager:
+		c.run = run
+		c.PerError = config.JUnitPerError
+	}
+}
+
+// validateOutputFlags returns an error for any of the output flags in config
+// which the selected output formats can't honour, rather than silently
+// ignoring them
+func validateOutputFlags(config *Config) error {
+	if config.OutputJSONFile != "" && config.OutputFormat == outputJSON && config.OutputDir == "" {
+		return fmt.Errorf("--output-json cannot be combined with --output %s, as the console output is already JSON", outputJSON)
+	}
+	if (config.RunMetadata || config.RunID != "") && !carriesRunMetadata(config) {
+		return fmt.Errorf("--run-metadata and --run-id require --output %s or --output %s, or a JSON report written with --output-json", outputJSON, outputJUnit)
+	}
+	if config.JUnitPerError && config.OutputFormat != outputJUnit {
+		return fmt.Errorf("--junit-per-error requires --output %s", outputJUnit)
+	}
+	if config.GroupByFile && (config.OutputDir != "" || (config.OutputFormat != "" && config.OutputFormat != outputSTD)) {
+		return fmt.Errorf("--group-by-file requires --output %s", outputSTD)
+	}
+	if config.SummaryByNamespace && !carriesSummary(config) {
+		return fmt.Errorf("--summary-by-namespace requires --output %s or --output %s, or a JSON report written with --output-json", outputSTD, outputJSON)
+	}
+	return nil
+}
+
+// carriesRunMetadata returns whether any of the outputs selected in config
+// include the run metadata, which only the JSON and JUnit formats do
+func carriesRunMetadata(config *Config) bool {
+	if config.OutputJSONFile != "" {
+		return true
+	}
+	if config.OutputDir != "" {
+		return config.OutputFormat == outputJSON
+	}
+	return config.OutputFormat == outputJSON || config.OutputFormat == outputJUnit
+}
+
+// carriesSummary returns whether any of the outputs selected in config
+// include a summary of the results, which only the stdout and JSON formats do
+func carriesSummary(config *Config) bool {
+	if config.OutputJSONFile != "" {
+		return true
+	}
+	if config.OutputDir != "" {
+		return false
+	}
+	return config.OutputFormat == "" || config.OutputFormat == outputSTD || config.OutputFormat == outputJSON
+}
+
+// runMetadata identifies the run which produced a report, so that stored
+// reports are self-describing
+type runMetadata struct {
+	RunID     string `json:"runId"`
+	Timestamp string `json:"timestamp"`
+}
+
+// newRunMetadata returns metadata for a run starting now, generating a
+// random run ID if none is given
+func newRunMetadata(runID string) (*runMetadata, error) {
+	if runID == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("Failed to generate a run ID: %s", err.Error())
+		}
+		runID = hex.EncodeToString(b)
+	}
+	return &runMetadata{
+		RunID:     runID,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// jsonRunOutput wraps JSON results along with the metadata of their run,
+// and a summary of them, when either is included
+type jsonRunOutput struct {
+	*runMetadata
+	Summary *summary         `json:"summary,omitempty"`
+	Results []dataEvalResult `json:"results"`
+}
+
+// multiOutputManager reports results to several output managers at once.
+type multiOutputManager struct {
+	managers []outputManager
+}
+
+func newMultiOutputManager(managers ...outputManager) *multiOutputManager {
+	return &multiOutputManager{
+		managers: managers,
 	}
 }
 
+func (m *multiOutputManager) Put(r ValidationResult) error {
+	for _, manager := range m.managers {
+		if err := manager.Put(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *multiOutputManager) HasErrors() bool {
+	for _, manager := range m.managers {
+		if manager.HasErrors() {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *multiOutputManager) Flush() error {
+	for _, manager := range m.managers {
+		if err := manager.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // STDOutputManager reports `kubeval` results to stdout.
 type STDOutputManager struct {
+	invalidCount
+
+	w io.Writer
+
+	// mu guards the counts, and keeps the lines for each result together
+	mu sync.Mutex
+
+	// counts of results by status, and the files they came from, for
+	// the summary printed on Flush
+	summary *summary
+	files   map[string]bool
+
+	// grouped holds the lines for each file with GroupByFile, in the order
+	// the files were first seen
+	grouped   map[string]*bytes.Buffer
+	fileNames []string
+
 	FailuresOnly bool
+	SkipWarnings bool
+	// GroupByFile buffers the results until Flush, and then prints them
+	// grouped under a header for each file, instead of as they are put
+	GroupByFile bool
+	// Color colors the status of each line
+	Color bool
+	// ByNamespace adds the counts for each namespace to the summary
+	ByNamespace bool
 }
 
-// newSTDOutputManager instantiates a new instance of STDOutputManager.
-func newSTDOutputManager(failuresOnly bool) *STDOutputManager {
+// newSTDOutputManager instantiates a new instance of STDOutputManager
+// which writes to w.
+func newSTDOutputManager(w io.Writer, failuresOnly, skipWarnings bool) *STDOutputManager {
+	color, _ := UseColor(ColorAuto, w)
 	return &STDOutputManager{
+		w:            w,
+		summary:      newSummary(NewDefaultConfig().DefaultNamespace),
+		files:        map[string]bool{},
+		grouped:      map[string]*bytes.Buffer{},
 		FailuresOnly: failuresOnly,
+		SkipWarnings: skipWarnings,
+		Color:        color,
 	}
 }
 
 func (s *STDOutputManager) Put(result ValidationResult) error {
+	s.record(result)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.summary.add(result)
+	s.files[result.FileName] = true
+
+	w := s.w
+	if s.GroupByFile {
+		buf, ok := s.grouped[result.FileName]
+		if !ok {
+			buf = new(bytes.Buffer)
+			s.grouped[result.FileName] = buf
+			s.fileNames = append(s.fileNames, result.FileName)
+		}
+		w = buf
+	}
+	out := kLog.Printer{W: w, Color: s.Color}
+
 	if len(result.Errors) > 0 {
 		for _, desc := range result.Errors {
-			kLog.Warn(result.FileName, "contains an invalid", result.Kind, fmt.Sprintf("(%s)", result.QualifiedName()), "-", desc.String())
+			// lead with file:line:column when the position is known, so
+			// editors and terminals can jump to the offending node
+			location := result.FileName
+			if line, column, ok := errorPosition(desc); ok {
+				location = fmt.Sprintf("%s:%d:%d", result.FileName, line, column)
+			}
+			out.Warn(location, "contains an invalid", result.Kind, fmt.Sprintf("(%s)", result.QualifiedName()), "-", desc.String())
 		}
 	} else if result.Kind == "" && !s.FailuresOnly {
-		kLog.Success(result.FileName, "contains an empty YAML document")
+		if !s.SkipWarnings {
+			out.Success(result.FileName, "contains an empty YAML document")
+		}
 	} else if !result.ValidatedAgainstSchema {
-		kLog.Warn(result.FileName, "containing a", result.Kind, fmt.Sprintf("(%s)", result.QualifiedName()), "was not validated against a schema")
-	} else if !s.FailuresOnly {
-		kLog.Success(result.FileName, "contains a valid", result.Kind, fmt.Sprintf("(%s)", result.QualifiedName()))
+		if !s.SkipWarnings {
+			out.Warn(result.FileName, "containing a", result.Kind, fmt.Sprintf("(%s)", result.QualifiedName()), "was not validated against a schema")
+		}
+	} else if !s.FailuresOnly && !hasErrors(result) {
+		out.Success(result.FileName, "contains a valid", result.Kind, fmt.Sprintf("(%s)", result.QualifiedName()))
+	}
+
+	for _, f := range result.Findings {
+		switch f.Severity {
+		case SeverityInfo:
+			out.Info(result.FileName, "contains a", result.Kind, fmt.Sprintf("(%s)", result.QualifiedName()), "-", f.String())
+		case SeverityError:
+			out.Warn(result.FileName, "contains an invalid", result.Kind, fmt.Sprintf("(%s)", result.QualifiedName()), "-", f.String())
+		default:
+			out.Warn(result.FileName, "contains a", result.Kind, fmt.Sprintf("(%s)", result.QualifiedName()), "-", f.String())
+		}
 	}
 
 	return nil
 }
 
+// Flush prints the results for each file with GroupByFile, then a summary
+// of the results, which includes valid results even when they were left out
+// with FailuresOnly
 func (s *STDOutputManager) Flush() error {
-	// no op
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, fileName := range s.fileNames {
+		buf := s.grouped[fileName]
+		// files with nothing to report, such as with FailuresOnly, are left out
+		if buf.Len() == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(s.w, "=== %s ===\n", fileName); err != nil {
+			return err
+		}
+		if _, err := buf.WriteTo(s.w); err != nil {
+			return err
+		}
+	}
+
+	files := "files"
+	if len(s.files) == 1 {
+		files = "file"
+	}
+	if _, err := fmt.Fprintf(s.w, "Summary: %s across %d %s\n", s.summary.summaryCounts, len(s.files), files); err != nil {
+		return err
+	}
+	if !s.ByNamespace {
+		return nil
+	}
+	for _, namespace := range s.summary.namespaces() {
+		if _, err := fmt.Fprintf(s.w, "  %s: %s\n", namespace, s.summary.Namespaces[namespace]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -90,72 +479,182 @@ const (
 	statusSkipped = "skipped"
 )
 
+// skipReason is why a result was skipped rather than validated
+type skipReason string
+
+const (
+	skipEmptyDocument skipReason = "empty_document"
+	// skipNoSchemaFound is also the reason for kinds skipped with KindsToSkip,
+	// as neither is validated against a schema
+	skipNoSchemaFound skipReason = "no_schema_found"
+)
+
 type dataEvalResult struct {
-	Filename string   `json:"filename"`
-	Kind     string   `json:"kind"`
-	Status   status   `json:"status"`
-	Errors   []string `json:"errors"`
+	Filename   string          `json:"filename"`
+	Kind       string          `json:"kind"`
+	Status     status          `json:"status"`
+	SkipReason skipReason      `json:"skipReason,omitempty"`
+	Errors     []dataEvalError `json:"errors"`
+	Findings   []Finding       `json:"findings,omitempty"`
+}
+
+// dataEvalError is a single error of a result, with the line and column of
+// the offending node when they are known
+type dataEvalError struct {
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
 }
 
 // jsonOutputManager reports `ccheck` results to `stdout` as a json array..
 type jsonOutputManager struct {
+	invalidCount
+
 	logger *log.Logger
 
+	// mu guards data and summary
+	mu   sync.Mutex
 	data []dataEvalResult
 
-	FailuresOnly bool
-}
+	// run is included in the output when set, wrapping the results
+	run *runMetadata
+	// summary counts every result put, including those left out, and is
+	// included in the output when set, wrapping the results
+	summary *summary
 
-func newDefaultJSONOutputManager(failuresOnly bool) *jsonOutputManager {
-	return newJSONOutputManager(log.New(os.Stdout, "", 0), failuresOnly)
+	FailuresOnly bool
+	SkipWarnings bool
 }
 
-func newJSONOutputManager(l *log.Logger, failuresOnly bool) *jsonOutputManager {
+func newJSONOutputManager(l *log.Logger, failuresOnly, skipWarnings bool) *jsonOutputManager {
 	return &jsonOutputManager{
 		logger: l,
+		// an empty slice, so that the json has an empty array rather than
+		// null when no results are recorded
+		data:         []dataEvalResult{},
 		FailuresOnly: failuresOnly,
+		SkipWarnings: skipWarnings,
 	}
 }
 
-func getStatus(r ValidationResult) status {
+// getSkipReason returns why r was skipped, or an empty reason if it wasn't
+func getSkipReason(r ValidationResult) skipReason {
 	if r.Kind == "" {
-		return statusSkipped
+		return skipEmptyDocument
 	}
 
 	if !r.ValidatedAgainstSchema {
-		return statusSkipped
+		return skipNoSchemaFound
 	}
 
+	return ""
+}
+
+// hideValid returns whether r is left out of the output with FailuresOnly,
+// which leaves out valid results unless they have findings to report
+func hideValid(r ValidationResult, failuresOnly bool) bool {
+	return failuresOnly && getStatus(r) == statusValid && len(r.Findings) == 0
+}
+
+// hideSkipped returns whether r is left out of the output with SkipWarnings,
+// which leaves out skipped results unless they have findings to report
+func hideSkipped(r ValidationResult, skipWarnings bool) bool {
+	return skipWarnings && getStatus(r) == statusSkipped && len(r.Findings) == 0
+}
+
+// hasErrors returns whether r fails validation, as it has schema errors or
+// findings with error severity
+func hasErrors(r ValidationResult) bool {
 	if len(r.Errors) > 0 {
+		return true
+	}
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// getStatus returns the status of r, which is invalid whenever hasErrors is
+// so that it always agrees with the exit code
+func getStatus(r ValidationResult) status {
+	if hasErrors(r) {
 		return statusInvalid
 	}
 
+	if r.Kind == "" {
+		return statusSkipped
+	}
+
+	if !r.ValidatedAgainstSchema {
+		return statusSkipped
+	}
+
 	return statusValid
 }
 
-func (j *jsonOutputManager) Put(r ValidationResult) error {
+// newDataEvalResult returns the record reported for r by the JSON formats
+func newDataEvalResult(r ValidationResult) dataEvalResult {
 	// stringify gojsonschema errors
 	// use a pre-allocated slice to ensure the json will have an
 	// empty array in the "zero" case
-	errs := make([]string, 0, len(r.Errors))
+	errs := make([]dataEvalError, 0, len(r.Errors))
 	for _, e := range r.Errors {
-		errs = append(errs, e.String())
+		line, column, _ := errorPosition(e)
+		errs = append(errs, dataEvalError{
+			Message: e.String(),
+			Line:    line,
+			Column:  column,
+		})
 	}
 
-	if getStatus(r) == statusValid && !j.FailuresOnly {
-		j.data = append(j.data, dataEvalResult{
-			Filename: r.FileName,
-			Kind:     r.Kind,
-			Status:   getStatus(r),
-			Errors:   errs,
-		})
+	return dataEvalResult{
+		Filename:   r.FileName,
+		Kind:       r.Kind,
+		Status:     getStatus(r),
+		SkipReason: getSkipReason(r),
+		Errors:     errs,
+		Findings:   r.Findings,
 	}
+}
+
+func (j *jsonOutputManager) Put(r ValidationResult) error {
+	j.record(r)
+	if j.summary != nil {
+		j.mu.Lock()
+		j.summary.add(r)
+		j.mu.Unlock()
+	}
+
+	// with FailuresOnly, only valid results are left out
+	if hideValid(r, j.FailuresOnly) {
+		return nil
+	}
+	if hideSkipped(r, j.SkipWarnings) {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.data = append(j.data, newDataEvalResult(r))
 
 	return nil
 }
 
 func (j *jsonOutputManager) Flush() error {
-	b, err := json.Marshal(j.data)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var v interface{} = j.data
+	if j.run != nil || j.summary != nil {
+		v = jsonRunOutput{
+			runMetadata: j.run,
+			Summary:     j.summary,
+			Results:     j.data,
+		}
+	}
+	b, err := json.Marshal(v)
 	if err != nil {
 		return err
 	}
@@ -170,84 +669,808 @@ func (j *jsonOutputManager) Flush() error {
 	return nil
 }
 
+// jsonlOutputManager streams `kubeval` results to stdout as newline
+// delimited JSON, writing each result as soon as it is put.
+type jsonlOutputManager struct {
+	invalidCount
+
+	logger *log.Logger
+
+	FailuresOnly bool
+	SkipWarnings bool
+}
+
+func newJSONLOutputManager(l *log.Logger, failuresOnly, skipWarnings bool) *jsonlOutputManager {
+	return &jsonlOutputManager{
+		logger:       l,
+		FailuresOnly: failuresOnly,
+		SkipWarnings: skipWarnings,
+	}
+}
+
+func (j *jsonlOutputManager) Put(r ValidationResult) error {
+	j.record(r)
+
+	// with FailuresOnly, only valid results are left out
+	if hideValid(r, j.FailuresOnly) {
+		return nil
+	}
+	if hideSkipped(r, j.SkipWarnings) {
+		return nil
+	}
+
+	b, err := json.Marshal(newDataEvalResult(r))
+	if err != nil {
+		return err
+	}
+
+	// the logger serialises writes, so lines are never interleaved
+	j.logger.Print(string(b))
+	return nil
+}
+
+func (j *jsonlOutputManager) Flush() error {
+	// no op, as results are written as they are put
+	return nil
+}
+
+// fileOutputManager buffers the output of a machine readable format,
+// writing it to a file in its entirety on Flush.
+type fileOutputManager struct {
+	outputManager
+
+	path string
+	buf  *bytes.Buffer
+}
+
+func newFileOutputManager(outFmt string, path string, failuresOnly, skipWarnings bool, run *runMetadata) (*fileOutputManager, error) {
+	buf := new(bytes.Buffer)
+	l := log.New(buf, "", 0)
+	var m outputManager
+	switch outFmt {
+	case outputJSON:
+		j := newJSONOutputManager(l, failuresOnly, skipWarnings)
+		j.run = run
+		m = j
+	case outputTAP:
+		m = newTAPOutputManager(l, failuresOnly, skipWarnings)
+	default:
+		return nil, fmt.Errorf("Results can only be written to files as %s or %s, not %s", outputJSON, outputTAP, outFmt)
+	}
+	return &fileOutputManager{
+		outputManager: m,
+		path:          path,
+		buf:           buf,
+	}, nil
+}
+
+func (f *fileOutputManager) Flush() error {
+	err := f.outputManager.Flush()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(f.path), 0755)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f.path, f.buf.Bytes(), 0644)
+}
+
+// dirOutputManager writes the results for each input file to a file of its
+// own in a directory, mirroring the layout of the inputs.
+type dirOutputManager struct {
+	dir          string
+	outFmt       string
+	failuresOnly bool
+	skipWarnings bool
+	run          *runMetadata
+
+	// mu guards paths and managers, which are keyed by the path of the
+	// output file, so that names for the same input such as a.yaml and
+	// ./a.yaml share a file rather than overwriting each other
+	mu       sync.Mutex
+	paths    []string
+	managers map[string]*fileOutputManager
+}
+
+func newDirOutputManager(dir string, outFmt string, failuresOnly, skipWarnings bool, run *runMetadata) (*dirOutputManager, error) {
+	if outFmt != outputJSON && outFmt != outputTAP {
+		return nil, fmt.Errorf("--output-dir requires --output %s or --output %s", outputJSON, outputTAP)
+	}
+	return &dirOutputManager{
+		dir:          dir,
+		outFmt:       outFmt,
+		failuresOnly: failuresOnly,
+		skipWarnings: skipWarnings,
+		run:          run,
+		managers:     map[string]*fileOutputManager{},
+	}, nil
+}
+
+func (d *dirOutputManager) Put(r ValidationResult) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	path := outputDirPath(d.dir, r.FileName, d.outFmt)
+	m, ok := d.managers[path]
+	if !ok {
+		var err error
+		m, err = newFileOutputManager(d.outFmt, path, d.failuresOnly, d.skipWarnings, d.run)
+		if err != nil {
+			return err
+		}
+		d.managers[path] = m
+		d.paths = append(d.paths, path)
+	}
+	return m.Put(r)
+}
+
+func (d *dirOutputManager) HasErrors() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, m := range d.managers {
+		if m.HasErrors() {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *dirOutputManager) Flush() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, path := range d.paths {
+		if err := d.managers[path].Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputDirPath returns the path within dir of the results for fileName.
+// Absolute paths, and relative paths outside of the working directory, are
+// placed beneath dir rather than escaping it.
+func outputDirPath(dir string, fileName string, outFmt string) string {
+	p := filepath.Clean(fileName)
+	p = strings.TrimPrefix(p, filepath.VolumeName(p))
+	var parts []string
+	for _, part := range strings.Split(filepath.ToSlash(p), "/") {
+		if part != "" && part != "." && part != ".." {
+			parts = append(parts, part)
+		}
+	}
+	return filepath.Join(dir, filepath.Join(parts...)) + "." + outFmt
+}
+
 // tapOutputManager reports `conftest` results to stdout.
 type tapOutputManager struct {
+	invalidCount
+
 	logger *log.Logger
 
+	// mu guards data
+	mu   sync.Mutex
 	data []dataEvalResult
 
 	FailuresOnly bool
-}
-
-// newDefaultTapOutManager instantiates a new instance of tapOutputManager
-// using the default logger.
-func newDefaultTAPOutputManager(failuresOnly bool) *tapOutputManager {
-	return newTAPOutputManager(log.New(os.Stdout, "", 0), failuresOnly)
+	SkipWarnings bool
 }
 
 // newTapOutputManager constructs an instance of tapOutputManager given a
 // logger instance.
-func newTAPOutputManager(l *log.Logger, failuresOnly bool) *tapOutputManager {
+func newTAPOutputManager(l *log.Logger, failuresOnly, skipWarnings bool) *tapOutputManager {
 	return &tapOutputManager{
-		logger: l,
+		logger:       l,
 		FailuresOnly: failuresOnly,
+		SkipWarnings: skipWarnings,
 	}
 }
 
 func (j *tapOutputManager) Put(r ValidationResult) error {
-	errs := make([]string, 0, len(r.Errors))
-	for _, e := range r.Errors {
-		errs = append(errs, e.String())
-	}
+	j.record(r)
 
-	if getStatus(r) == statusValid && !j.FailuresOnly {
-		j.data = append(j.data, dataEvalResult{
-			Filename: r.FileName,
-			Kind:     r.Kind,
-			Status:   getStatus(r),
-			Errors:   errs,
-		})
+	// with FailuresOnly, only valid results are left out
+	if hideValid(r, j.FailuresOnly) {
+		return nil
+	}
+	if hideSkipped(r, j.SkipWarnings) {
+		return nil
 	}
 
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.data = append(j.data, newDataEvalResult(r))
+
 	return nil
 }
 
 func (j *tapOutputManager) Flush() error {
-	issues := len(j.data)
-	if issues > 0 {
-		total := 0
-		for _, r := range j.data {
-			if len(r.Errors) > 0 {
-				total = total + len(r.Errors)
-			} else {
-				total = total + 1
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	total := 0
+	for _, r := range j.data {
+		if len(r.Errors) > 0 {
+			total = total + len(r.Errors)
+		} else {
+			total = total + 1
+		}
+		// each finding is a test point of its own
+		total = total + len(r.Findings)
+	}
+	// the version and plan are printed even without any test points, as
+	// consumers such as TAP::Harness fail on output without a plan
+	j.logger.Print("TAP version 13")
+	j.logger.Print(fmt.Sprintf("1..%d", total))
+	count := 0
+	for _, r := range j.data {
+		var kindMarker string
+		if r.Kind == "" {
+			kindMarker = ""
+		} else {
+			kindMarker = fmt.Sprintf(" (%s)", r.Kind)
+		}
+		// the schema validation of the resource is a test point of its own,
+		// separate from any findings, so results which are only invalid
+		// because of their findings still pass it
+		if len(r.Errors) > 0 {
+			// each error is a test point of its own, followed by its
+			// diagnostics
+			for _, e := range r.Errors {
+				count = count + 1
+				j.logger.Print("not ok ", count, " - ", r.Filename, kindMarker)
+				j.logger.Print(tapDiagnostics(r, e.Message, "fail"))
 			}
+		} else if r.SkipReason != "" {
+			count = count + 1
+			j.logger.Print("ok ", count, " - ", r.Filename, kindMarker, " # SKIP ", r.SkipReason)
+		} else {
+			count = count + 1
+			j.logger.Print("ok ", count, " - ", r.Filename, kindMarker)
 		}
-		j.logger.Print(fmt.Sprintf("1..%d", total))
-		count := 0
-		for _, r := range j.data {
+		// findings with error severity fail, warnings are marked TODO so
+		// that they are reported without failing, and info passes
+		for _, f := range r.Findings {
 			count = count + 1
-			var kindMarker string
-			if r.Kind == "" {
-				kindMarker = ""
+			switch f.Severity {
+			case SeverityError:
+				j.logger.Print("not ok ", count, " - ", r.Filename, kindMarker)
+			case SeverityWarning:
+				j.logger.Print("not ok ", count, " - ", r.Filename, kindMarker, " # TODO ", f.CheckID)
+			default:
+				j.logger.Print("ok ", count, " - ", r.Filename, kindMarker)
+			}
+			j.logger.Print(tapDiagnostics(r, f.String(), string(f.Severity)))
+		}
+	}
+	return nil
+}
+
+// tapDiagnostics returns the YAML diagnostic block for a single error or
+// finding of a result, indented to attach to the test point before it.
+// Values are quoted as JSON strings, which are also valid YAML
+func tapDiagnostics(r dataEvalResult, message string, severity string) string {
+	lines := []string{"  ---"}
+	for _, field := range []struct{ key, value string }{
+		{"message", message},
+		{"severity", severity},
+		{"kind", r.Kind},
+		{"filename", r.Filename},
+	} {
+		value, _ := json.Marshal(field.value)
+		lines = append(lines, fmt.Sprintf("  %s: %s", field.key, value))
+	}
+	lines = append(lines, "  ...")
+	return strings.Join(lines, "\n")
+}
+
+// junitOutputManager reports `kubeval` results to stdout as a JUnit XML
+// document, with a test case for each resource.
+type junitOutputManager struct {
+	invalidCount
+
+	logger *log.Logger
+
+	// mu guards results
+	mu      sync.Mutex
+	results []ValidationResult
+
+	// run is included in the properties of the test suite when set
+	run *runMetadata
+
+	FailuresOnly bool
+	SkipWarnings bool
+	// PerError reports each error, and each finding with error severity, as
+	// a test case of its own instead of as failures of the resource's test
+	// case. Resources without errors still have a single test case
+	PerError bool
+}
+
+func newJUnitOutputManager(l *log.Logger, failuresOnly, skipWarnings bool) *junitOutputManager {
+	return &junitOutputManager{
+		logger:       l,
+		FailuresOnly: failuresOnly,
+		SkipWarnings: skipWarnings,
+	}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Properties *junitProperties `xml:"properties"`
+	TestCases  []junitTestCase  `xml:"testcase"`
+}
+
+type junitProperties struct {
+	Properties []junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+	Skipped   *junitSkipped  `xml:"skipped"`
+	SystemOut string         `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+func (j *junitOutputManager) Put(r ValidationResult) error {
+	j.record(r)
+
+	// with FailuresOnly, only valid results are left out
+	if hideValid(r, j.FailuresOnly) {
+		return nil
+	}
+	if hideSkipped(r, j.SkipWarnings) {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.results = append(j.results, r)
+	return nil
+}
+
+func (j *junitOutputManager) Flush() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	suite := junitTestSuite{
+		Name:      "kubeval",
+		TestCases: []junitTestCase{},
+	}
+	if j.run != nil {
+		suite.Properties = &junitProperties{
+			Properties: []junitProperty{
+				{Name: "runId", Value: j.run.RunID},
+				{Name: "timestamp", Value: j.run.Timestamp},
+			},
+		}
+	}
+	for _, r := range j.results {
+		tc := junitTestCase{
+			Name:      r.QualifiedName(),
+			ClassName: r.FileName,
+		}
+		// subjects names what each failure is about, to tell apart the test
+		// cases for each failure with PerError
+		var subjects []string
+		for _, e := range r.Errors {
+			tc.Failures = append(tc.Failures, junitFailure{
+				Message: e.String(),
+				Type:    e.Type(),
+				Text:    e.String(),
+			})
+			subjects = append(subjects, e.Field())
+		}
+		// findings with error severity fail the test case in the same way
+		// as schema errors, and other findings are written to its output
+		var notes []string
+		for _, f := range r.Findings {
+			if f.Severity == SeverityError {
+				tc.Failures = append(tc.Failures, junitFailure{
+					Message: f.String(),
+					Type:    f.CheckID,
+					Text:    f.String(),
+				})
+				subjects = append(subjects, f.CheckID)
 			} else {
-				kindMarker = fmt.Sprintf(" (%s)", r.Kind)
+				notes = append(notes, fmt.Sprintf("%s: %s", f.Severity, f.String()))
 			}
-			if r.Status == "valid" {
-				j.logger.Print("ok ", count, " - ", r.Filename, kindMarker)
-			} else if r.Status == "skipped" {
-				j.logger.Print("ok ", count, " - ", r.Filename, kindMarker, " # SKIP")
-			} else if r.Status == "invalid" {
-				for i, e := range r.Errors {
-					j.logger.Print("not ok ", count, " - ", r.Filename, kindMarker, " - ", e)
-
-					// We have to skip adding 1 if it's the last error
-					if len(r.Errors) != i+1 {
-						count = count + 1
-					}
+		}
+		tc.SystemOut = strings.Join(notes, "\n")
+
+		if len(tc.Failures) > 0 && j.PerError {
+			// each failure is a test case of its own, in the same way as the
+			// TAP output has a test point for each error, with the output of
+			// the resource on the first
+			for i, failure := range tc.Failures {
+				errorCase := junitTestCase{
+					Name:      fmt.Sprintf("%s (%s)", tc.Name, subjects[i]),
+					ClassName: tc.ClassName,
+					Failures:  []junitFailure{failure},
+				}
+				if i == 0 {
+					errorCase.SystemOut = tc.SystemOut
 				}
+				suite.TestCases = append(suite.TestCases, errorCase)
+				suite.Failures++
 			}
+			continue
+		}
+		if len(tc.Failures) > 0 {
+			suite.Failures++
+		} else if getStatus(r) == statusSkipped {
+			message := "not validated against a schema"
+			if r.Kind == "" {
+				message = "empty document"
+			}
+			tc.Skipped = &junitSkipped{Message: message}
+			suite.Skipped++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	suite.Tests = len(suite.TestCases)
+
+	b, err := xml.MarshalIndent(junitTestSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Suites:   []junitTestSuite{suite},
+	}, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	j.logger.Print(xml.Header + string(b))
+	return nil
+}
+
+// githubOutputManager reports `kubeval` results to stdout as GitHub Actions
+// workflow commands, so that problems are annotated on the files in a pull
+// request. Valid results are not reported.
+type githubOutputManager struct {
+	invalidCount
+
+	logger *log.Logger
+
+	// mu keeps the lines for each result together
+	mu sync.Mutex
+
+	SkipWarnings bool
+}
+
+func newGithubOutputManager(l *log.Logger, skipWarnings bool) *githubOutputManager {
+	return &githubOutputManager{
+		logger:       l,
+		SkipWarnings: skipWarnings,
+	}
+}
+
+// githubCommands maps finding severities to the workflow command which
+// reports them
+var githubCommands = map[Severity]string{
+	SeverityInfo:    "notice",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+}
+
+// escapeGithubData escapes the message of a workflow command
+func escapeGithubData(s string) string {
+	s = strings.Replace(s, "%", "%25", -1)
+	s = strings.Replace(s, "\r", "%0D", -1)
+	return strings.Replace(s, "\n", "%0A", -1)
+}
+
+// escapeGithubProperty escapes a property value of a workflow command
+func escapeGithubProperty(s string) string {
+	s = escapeGithubData(s)
+	s = strings.Replace(s, ":", "%3A", -1)
+	return strings.Replace(s, ",", "%2C", -1)
+}
+
+func (g *githubOutputManager) command(command, fileName, message string) {
+	g.logger.Print(fmt.Sprintf("::%s file=%s::%s", command, escapeGithubProperty(fileName), escapeGithubData(message)))
+}
+
+// errorCommand prints an error annotation, on the line of the offending node
+// when it is known
+func (g *githubOutputManager) errorCommand(fileName string, e gojsonschema.ResultError, message string) {
+	line, column, ok := errorPosition(e)
+	if !ok {
+		g.command("error", fileName, message)
+		return
+	}
+	g.logger.Print(fmt.Sprintf("::error file=%s,line=%d,col=%d::%s", escapeGithubProperty(fileName), line, column, escapeGithubData(message)))
+}
+
+func (g *githubOutputManager) Put(r ValidationResult) error {
+	g.record(r)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	resource := fmt.Sprintf("%s (%s)", r.Kind, r.QualifiedName())
+	for _, e := range r.Errors {
+		g.errorCommand(r.FileName, e, fmt.Sprintf("%s - %s", resource, e.String()))
+	}
+	if r.Kind != "" && !r.ValidatedAgainstSchema && !g.SkipWarnings {
+		g.command("warning", r.FileName, fmt.Sprintf("%s was not validated against a schema", resource))
+	}
+	for _, f := range r.Findings {
+		g.command(githubCommands[f.Severity], r.FileName, fmt.Sprintf("%s - %s", resource, f.String()))
+	}
+	return nil
+}
+
+func (g *githubOutputManager) Flush() error {
+	// no op, as annotations are printed as results are put
+	return nil
+}
+
+// TemplateResult is the data available to templates used with the template
+// output format
+type TemplateResult struct {
+	FileName      string
+	Kind          string
+	QualifiedName string
+	Status        string
+	Errors        []string
+	// Findings are the findings of the optional checks, which print as
+	// `check: message` and also have CheckID, Severity, Path and Message
+	Findings []Finding
+}
+
+// templateOutputManager reports `kubeval` results to stdout using a
+// text/template, which is executed for each result as it is put.
+type templateOutputManager struct {
+	invalidCount
+
+	logger *log.Logger
+	tmpl   *template.Template
+
+	FailuresOnly bool
+	SkipWarnings bool
+}
+
+func newTemplateOutputManager(l *log.Logger, tmpl string, failuresOnly, skipWarnings bool) (*templateOutputManager, error) {
+	t, err := template.New("output").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse output template: %s", err.Error())
+	}
+	return &templateOutputManager{
+		logger:       l,
+		tmpl:         t,
+		FailuresOnly: failuresOnly,
+		SkipWarnings: skipWarnings,
+	}, nil
+}
+
+func (t *templateOutputManager) Put(r ValidationResult) error {
+	t.record(r)
+
+	// with FailuresOnly, only valid results are left out
+	if hideValid(r, t.FailuresOnly) {
+		return nil
+	}
+	if hideSkipped(r, t.SkipWarnings) {
+		return nil
+	}
+
+	errs := make([]string, 0, len(r.Errors))
+	for _, e := range r.Errors {
+		errs = append(errs, e.String())
+	}
+
+	var out bytes.Buffer
+	err := t.tmpl.Execute(&out, TemplateResult{
+		FileName:      r.FileName,
+		Kind:          r.Kind,
+		QualifiedName: r.QualifiedName(),
+		Status:        string(getStatus(r)),
+		Errors:        errs,
+		Findings:      r.Findings,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to execute output template for %s: %s", r.FileName, err.Error())
+	}
+
+	// the logger serialises writes, and ends each result with a newline
+	t.logger.Print(out.String())
+	return nil
+}
+
+func (t *templateOutputManager) Flush() error {
+	// no op, as results are written as they are put
+	return nil
+}
+
+// csvHeader is the first row of the csv output format
+var csvHeader = []string{"filename", "kind", "status", "error"}
+
+// csvOutputManager reports `kubeval` results to stdout as CSV, with a row
+// for each error and each finding of a result, or a single row for results
+// with neither.
+type csvOutputManager struct {
+	invalidCount
+
+	// mu guards w and header
+	mu     sync.Mutex
+	w      *csv.Writer
+	header bool
+
+	FailuresOnly bool
+	SkipWarnings bool
+}
+
+func newCSVOutputManager(w io.Writer, failuresOnly, skipWarnings bool) *csvOutputManager {
+	return &csvOutputManager{
+		w:            csv.NewWriter(w),
+		FailuresOnly: failuresOnly,
+		SkipWarnings: skipWarnings,
+	}
+}
+
+// writeHeader writes the header row, unless it has already been written.
+// The caller must hold c.mu
+func (c *csvOutputManager) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+	return c.w.Write(csvHeader)
+}
+
+func (c *csvOutputManager) Put(r ValidationResult) error {
+	c.record(r)
+
+	// with FailuresOnly, only valid results are left out
+	if hideValid(r, c.FailuresOnly) {
+		return nil
+	}
+	if hideSkipped(r, c.SkipWarnings) {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	status := getStatus(r)
+	problems := resultProblems(r)
+	if len(problems) == 0 {
+		return c.w.Write([]string{r.FileName, r.Kind, string(status), ""})
+	}
+	for _, problem := range problems {
+		if err := c.w.Write([]string{r.FileName, r.Kind, string(status), problem}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resultProblems returns the errors of r, followed by its findings prefixed
+// with their severity, for the formats which report both in a single column
+func resultProblems(r ValidationResult) []string {
+	problems := make([]string, 0, len(r.Errors)+len(r.Findings))
+	for _, e := range r.Errors {
+		problems = append(problems, e.String())
+	}
+	for _, f := range r.Findings {
+		problems = append(problems, fmt.Sprintf("%s: %s", f.Severity, f.String()))
+	}
+	return problems
+}
+
+// Flush writes any buffered rows, and the header if no results were put
+func (c *csvOutputManager) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// markdownStatuses marks the status of each result in the markdown output,
+// so that it can be skimmed
+var markdownStatuses = map[status]string{
+	statusValid:   "✅ valid",
+	statusInvalid: "❌ invalid",
+	statusSkipped: "⚠️ skipped",
+}
+
+// markdownOutputManager reports `kubeval` results to stdout as a Markdown
+// table, with a row for each resource, such as for comments on pull requests.
+type markdownOutputManager struct {
+	invalidCount
+
+	logger *log.Logger
+
+	// mu guards results
+	mu      sync.Mutex
+	results []ValidationResult
+
+	FailuresOnly bool
+	SkipWarnings bool
+}
+
+func newMarkdownOutputManager(l *log.Logger, failuresOnly, skipWarnings bool) *markdownOutputManager {
+	return &markdownOutputManager{
+		logger:       l,
+		FailuresOnly: failuresOnly,
+		SkipWarnings: skipWarnings,
+	}
+}
+
+// escapeMarkdownCell escapes s so that it stays within a single table cell
+func escapeMarkdownCell(s string) string {
+	s = strings.Replace(s, "\\", "\\\\", -1)
+	s = strings.Replace(s, "|", "\\|", -1)
+	s = strings.Replace(s, "\r\n", "<br>", -1)
+	return strings.Replace(s, "\n", "<br>", -1)
+}
+
+func (m *markdownOutputManager) Put(r ValidationResult) error {
+	m.record(r)
+
+	// with FailuresOnly, only valid results are left out
+	if hideValid(r, m.FailuresOnly) {
+		return nil
+	}
+	if hideSkipped(r, m.SkipWarnings) {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results = append(m.results, r)
+	return nil
+}
+
+func (m *markdownOutputManager) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.logger.Print("| File | Kind | Status | Errors |")
+	m.logger.Print("| --- | --- | --- | --- |")
+	for _, r := range m.results {
+		problems := resultProblems(r)
+		for i, problem := range problems {
+			problems[i] = escapeMarkdownCell(problem)
 		}
+		m.logger.Print(fmt.Sprintf("| %s | %s | %s | %s |",
+			escapeMarkdownCell(r.FileName),
+			escapeMarkdownCell(r.Kind),
+			markdownStatuses[getStatus(r)],
+			strings.Join(problems, "<br>"),
+		))
 	}
 	return nil
 }
@@ -2,7 +2,13 @@ package kubeval
 
 import (
 	"bytes"
+	"fmt"
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/xeipuuv/gojsonschema"
@@ -48,6 +54,7 @@ func Test_jsonOutputManager_put(t *testing.T) {
 		"filename": "",
 		"kind": "",
 		"status": "skipped",
+		"skipReason": "empty_document",
 		"errors": []
 	}
 ]
@@ -92,8 +99,12 @@ func Test_jsonOutputManager_put(t *testing.T) {
 		"kind": "service",
 		"status": "invalid",
 		"errors": [
-			"error: i am a error",
-			"error: i am another error"
+			{
+				"message": "error: i am a error"
+			},
+			{
+				"message": "error: i am another error"
+			}
 		]
 	}
 ]
@@ -103,7 +114,7 @@ func Test_jsonOutputManager_put(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.msg, func(t *testing.T) {
 			buf := new(bytes.Buffer)
-			s := newJSONOutputManager(log.New(buf, "", 0))
+			s := newJSONOutputManager(log.New(buf, "", 0), false, false)
 
 			// record results
 			err := s.Put(tt.args.vr)
@@ -122,6 +133,13 @@ func Test_jsonOutputManager_put(t *testing.T) {
 	}
 }
 
+func Test_jsonOutputManager_noResults(t *testing.T) {
+	buf := new(bytes.Buffer)
+	s := newJSONOutputManager(log.New(buf, "", 0), false, false)
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, "[]\n", buf.String())
+}
+
 func Test_tapOutputManager_put(t *testing.T) {
 	type args struct {
 		vr ValidationResult
@@ -143,7 +161,8 @@ func Test_tapOutputManager_put(t *testing.T) {
 					Errors:                 nil,
 				},
 			},
-			exp: `1..1
+			exp: `TAP version 13
+1..1
 ok 1 - deployment.yaml (Deployment)
 `,
 		},
@@ -160,9 +179,22 @@ ok 1 - deployment.yaml (Deployment)
 					}),
 				},
 			},
-			exp: `1..2
-not ok 1 - service.yaml (Service) - error: i am a error
-not ok 2 - service.yaml (Service) - error: i am another error
+			exp: `TAP version 13
+1..2
+not ok 1 - service.yaml (Service)
+  ---
+  message: "error: i am a error"
+  severity: "fail"
+  kind: "Service"
+  filename: "service.yaml"
+  ...
+not ok 2 - service.yaml (Service)
+  ---
+  message: "error: i am another error"
+  severity: "fail"
+  kind: "Service"
+  filename: "service.yaml"
+  ...
 `,
 		},
 		{
@@ -175,15 +207,68 @@ not ok 2 - service.yaml (Service) - error: i am another error
 					Errors:                 nil,
 				},
 			},
-			exp: `1..1
-ok 1 - deployment.yaml (Deployment) # SKIP
+			exp: `TAP version 13
+1..1
+ok 1 - deployment.yaml (Deployment) # SKIP no_schema_found
+`,
+		},
+		{
+			msg: "empty document",
+			args: args{
+				vr: ValidationResult{
+					FileName: "blank.yaml",
+				},
+			},
+			exp: `TAP version 13
+1..1
+ok 1 - blank.yaml # SKIP empty_document
+`,
+		},
+		{
+			msg: "file with findings",
+			args: args{
+				vr: ValidationResult{
+					FileName:               "pod.yaml",
+					Kind:                   "Pod",
+					ValidatedAgainstSchema: true,
+					Findings: []Finding{
+						{CheckID: "container-names", Severity: SeverityError, Message: "Nginx_Bad is not a valid DNS label"},
+						{CheckID: "labels", Severity: SeverityWarning, Message: "missing labels"},
+						{CheckID: "init-containers", Severity: SeverityInfo, Message: "many init containers"},
+					},
+				},
+			},
+			exp: `TAP version 13
+1..4
+ok 1 - pod.yaml (Pod)
+not ok 2 - pod.yaml (Pod)
+  ---
+  message: "container-names: Nginx_Bad is not a valid DNS label"
+  severity: "error"
+  kind: "Pod"
+  filename: "pod.yaml"
+  ...
+not ok 3 - pod.yaml (Pod) # TODO labels
+  ---
+  message: "labels: missing labels"
+  severity: "warning"
+  kind: "Pod"
+  filename: "pod.yaml"
+  ...
+ok 4 - pod.yaml (Pod)
+  ---
+  message: "init-containers: many init containers"
+  severity: "info"
+  kind: "Pod"
+  filename: "pod.yaml"
+  ...
 `,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.msg, func(t *testing.T) {
 			buf := new(bytes.Buffer)
-			s := newTAPOutputManager(log.New(buf, "", 0))
+			s := newTAPOutputManager(log.New(buf, "", 0), false, false)
 
 			// record results
 			err := s.Put(tt.args.vr)
@@ -201,3 +286,1327 @@ ok 1 - deployment.yaml (Deployment) # SKIP
 		})
 	}
 }
+
+func Test_tapOutputManager_noResults(t *testing.T) {
+	buf := new(bytes.Buffer)
+	s := newTAPOutputManager(log.New(buf, "", 0), true, false)
+	assert.NoError(t, s.Put(ValidationResult{
+		FileName:               "deployment.yaml",
+		Kind:                   "Deployment",
+		ValidatedAgainstSchema: true,
+	}))
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, "TAP version 13\n1..0\n", buf.String())
+}
+
+func Test_junitOutputManager(t *testing.T) {
+	buf := new(bytes.Buffer)
+	s := newJUnitOutputManager(log.New(buf, "", 0), false, false)
+	for _, r := range []ValidationResult{
+		{
+			FileName:               "deployment.yaml",
+			Kind:                   "Deployment",
+			ResourceName:           "web",
+			ValidatedAgainstSchema: true,
+		},
+		{
+			FileName:               "service.yaml",
+			Kind:                   "Service",
+			ResourceName:           "web",
+			ResourceNamespace:      "prod",
+			ValidatedAgainstSchema: true,
+			Errors: newResultErrors([]string{
+				"i am a <error> & more",
+				"i am another error",
+			}),
+		},
+		{
+			FileName:     "crd.yaml",
+			Kind:         "SealedSecret",
+			ResourceName: "token",
+		},
+		{
+			FileName:               "pod.yaml",
+			Kind:                   "Pod",
+			ResourceName:           "nginx",
+			ValidatedAgainstSchema: true,
+			Findings: []Finding{
+				{CheckID: "container-names", Severity: SeverityError, Message: "Nginx_Bad is not a valid DNS label"},
+				{CheckID: "labels", Severity: SeverityWarning, Message: "missing labels"},
+			},
+		},
+	} {
+		assert.NoError(t, s.Put(r))
+	}
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="4" failures="2" skipped="1">
+	<testsuite name="kubeval" tests="4" failures="2" skipped="1">
+		<testcase name="web" classname="deployment.yaml"></testcase>
+		<testcase name="prod.web" classname="service.yaml">
+			<failure message="error: i am a &lt;error&gt; &amp; more">error: i am a &lt;error&gt; &amp; more</failure>
+			<failure message="error: i am another error">error: i am another error</failure>
+		</testcase>
+		<testcase name="token" classname="crd.yaml">
+			<skipped message="not validated against a schema"></skipped>
+		</testcase>
+		<testcase name="nginx" classname="pod.yaml">
+			<failure message="container-names: Nginx_Bad is not a valid DNS label" type="container-names">container-names: Nginx_Bad is not a valid DNS label</failure>
+			<system-out>warning: labels: missing labels</system-out>
+		</testcase>
+	</testsuite>
+</testsuites>
+`, buf.String())
+}
+
+func Test_junitOutputManager_perError(t *testing.T) {
+	buf := new(bytes.Buffer)
+	s := newJUnitOutputManager(log.New(buf, "", 0), false, false)
+	s.PerError = true
+	for _, r := range []ValidationResult{
+		{
+			FileName:               "deployment.yaml",
+			Kind:                   "Deployment",
+			ResourceName:           "web",
+			ValidatedAgainstSchema: true,
+		},
+		{
+			FileName:               "service.yaml",
+			Kind:                   "Service",
+			ResourceName:           "web",
+			ValidatedAgainstSchema: true,
+			Errors:                 newResultErrors([]string{"i am a error", "i am another error"}),
+			Findings: []Finding{
+				{CheckID: "container-names", Severity: SeverityError, Message: "bad name"},
+				{CheckID: "labels", Severity: SeverityWarning, Message: "missing labels"},
+			},
+		},
+		{
+			FileName:     "crd.yaml",
+			Kind:         "SealedSecret",
+			ResourceName: "token",
+		},
+	} {
+		assert.NoError(t, s.Put(r))
+	}
+	assert.NoError(t, s.Flush())
+	// the totals reconcile, with a failure for each failing test case
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="5" failures="3" skipped="1">
+	<testsuite name="kubeval" tests="5" failures="3" skipped="1">
+		<testcase name="web" classname="deployment.yaml"></testcase>
+		<testcase name="web (error)" classname="service.yaml">
+			<failure message="error: i am a error">error: i am a error</failure>
+			<system-out>warning: labels: missing labels</system-out>
+		</testcase>
+		<testcase name="web (error)" classname="service.yaml">
+			<failure message="error: i am another error">error: i am another error</failure>
+		</testcase>
+		<testcase name="web (container-names)" classname="service.yaml">
+			<failure message="container-names: bad name" type="container-names">container-names: bad name</failure>
+		</testcase>
+		<testcase name="token" classname="crd.yaml">
+			<skipped message="not validated against a schema"></skipped>
+		</testcase>
+	</testsuite>
+</testsuites>
+`, buf.String())
+}
+
+func Test_junitOutputManager_runMetadata(t *testing.T) {
+	buf := new(bytes.Buffer)
+	s := newJUnitOutputManager(log.New(buf, "", 0), false, false)
+	s.run = &runMetadata{
+		RunID:     "build-42",
+		Timestamp: "2020-01-02T03:04:05Z",
+	}
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="0" failures="0" skipped="0">
+	<testsuite name="kubeval" tests="0" failures="0" skipped="0">
+		<properties>
+			<property name="runId" value="build-42"></property>
+			<property name="timestamp" value="2020-01-02T03:04:05Z"></property>
+		</properties>
+	</testsuite>
+</testsuites>
+`, buf.String())
+}
+
+func Test_githubOutputManager(t *testing.T) {
+	buf := new(bytes.Buffer)
+	s := newGithubOutputManager(log.New(buf, "", 0), false)
+	for _, r := range []ValidationResult{
+		{
+			FileName:               "deployment.yaml",
+			Kind:                   "Deployment",
+			ResourceName:           "web",
+			ValidatedAgainstSchema: true,
+		},
+		{
+			FileName:               "manifests/a,b:c.yaml",
+			Kind:                   "Service",
+			ResourceName:           "web",
+			ValidatedAgainstSchema: true,
+			Errors: newResultErrors([]string{
+				"100% invalid\nacross lines",
+			}),
+			Findings: []Finding{{
+				CheckID:  "service-selectors",
+				Severity: SeverityWarning,
+				Message:  "Selector matches no pods",
+			}},
+		},
+		{
+			FileName:     "crd.yaml",
+			Kind:         "SealedSecret",
+			ResourceName: "token",
+		},
+	} {
+		assert.NoError(t, s.Put(r))
+	}
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, `::error file=manifests/a%2Cb%3Ac.yaml::Service (web) - error: 100%25 invalid%0Aacross lines
+::warning file=manifests/a%2Cb%3Ac.yaml::Service (web) - service-selectors: Selector matches no pods
+::warning file=crd.yaml::SealedSecret (token) was not validated against a schema
+`, buf.String())
+}
+
+func Test_jsonlOutputManager(t *testing.T) {
+	buf := new(bytes.Buffer)
+	s := newJSONLOutputManager(log.New(buf, "", 0), true, false)
+	assert.NoError(t, s.Put(ValidationResult{
+		FileName:               "deployment.yaml",
+		Kind:                   "Deployment",
+		ValidatedAgainstSchema: true,
+	}))
+	assert.Equal(t, "", buf.String())
+
+	assert.NoError(t, s.Put(ValidationResult{
+		FileName:               "service.yaml",
+		Kind:                   "Service",
+		ValidatedAgainstSchema: true,
+		Errors:                 newResultErrors([]string{"i am a error"}),
+	}))
+	// results are written as they are put, before Flush
+	assert.Equal(t, `{"filename":"service.yaml","kind":"Service","status":"invalid","errors":[{"message":"error: i am a error"}]}
+`, buf.String())
+
+	assert.NoError(t, s.Put(ValidationResult{
+		FileName: "crd.yaml",
+		Kind:     "SealedSecret",
+	}))
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, `{"filename":"service.yaml","kind":"Service","status":"invalid","errors":[{"message":"error: i am a error"}]}
+{"filename":"crd.yaml","kind":"SealedSecret","status":"skipped","skipReason":"no_schema_found","errors":[]}
+`, buf.String())
+}
+
+func Test_tapOutputManager_numbering(t *testing.T) {
+	buf := new(bytes.Buffer)
+	s := newTAPOutputManager(log.New(buf, "", 0), false, false)
+	assert.NoError(t, s.Put(ValidationResult{
+		FileName:               "service.yaml",
+		Kind:                   "Service",
+		ValidatedAgainstSchema: true,
+		Errors:                 newResultErrors([]string{"i am a error", "i am another error"}),
+	}))
+	assert.NoError(t, s.Put(ValidationResult{
+		FileName:               "deployment.yaml",
+		Kind:                   "Deployment",
+		ValidatedAgainstSchema: true,
+	}))
+	assert.NoError(t, s.Flush())
+
+	// each diagnostic block follows the test point it belongs to
+	var points []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "ok ") || strings.HasPrefix(line, "not ok ") || strings.HasPrefix(line, "  message: ") {
+			points = append(points, line)
+		}
+	}
+	assert.Equal(t, []string{
+		"not ok 1 - service.yaml (Service)",
+		`  message: "error: i am a error"`,
+		"not ok 2 - service.yaml (Service)",
+		`  message: "error: i am another error"`,
+		"ok 3 - deployment.yaml (Deployment)",
+	}, points)
+	assert.True(t, strings.HasPrefix(buf.String(), "TAP version 13\n1..3\n"))
+}
+
+func Test_outputManagers_errorPositions(t *testing.T) {
+	result := ValidationResult{
+		FileName:               "deployment.yaml",
+		Kind:                   "Deployment",
+		ResourceName:           "web",
+		ValidatedAgainstSchema: true,
+		Errors: []gojsonschema.ResultError{
+			positionedResultError{ResultError: newResultError("i am a error"), line: 142, column: 7},
+			newResultError("i am another error"),
+		},
+	}
+	tests := []struct {
+		outFmt string
+		exp    []string
+	}{
+		{outputSTD, []string{
+			"WARN - deployment.yaml:142:7 contains an invalid Deployment (web) - error: i am a error\n",
+			"WARN - deployment.yaml contains an invalid Deployment (web) - error: i am another error\n",
+		}},
+		{outputJSONL, []string{
+			`"errors":[{"message":"error: i am a error","line":142,"column":7},{"message":"error: i am another error"}]`,
+		}},
+		{outputGithub, []string{
+			"::error file=deployment.yaml,line=142,col=7::Deployment (web) - error: i am a error\n",
+			"::error file=deployment.yaml::Deployment (web) - error: i am another error\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.outFmt, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			s, err := GetOutputManagerWithWriter(tt.outFmt, buf, false, false)
+			assert.NoError(t, err)
+			assert.NoError(t, s.Put(result))
+			assert.NoError(t, s.Flush())
+			for _, exp := range tt.exp {
+				assert.Contains(t, buf.String(), exp)
+			}
+		})
+	}
+}
+
+func Test_csvOutputManager(t *testing.T) {
+	results := []ValidationResult{
+		{
+			FileName:               "deployment.yaml",
+			Kind:                   "Deployment",
+			ValidatedAgainstSchema: true,
+		},
+		{
+			FileName:               "service.yaml",
+			Kind:                   "Service",
+			ValidatedAgainstSchema: true,
+			Errors: newResultErrors([]string{
+				"i am a error",
+				`i am "another", error`,
+			}),
+		},
+		{
+			FileName: "crd.yaml",
+			Kind:     "SealedSecret",
+		},
+		{
+			FileName:               "pod.yaml",
+			Kind:                   "Pod",
+			ValidatedAgainstSchema: true,
+			Findings: []Finding{
+				{CheckID: "container-names", Severity: SeverityError, Message: "Nginx_Bad is not a valid DNS label"},
+				{CheckID: "labels", Severity: SeverityWarning, Message: "missing labels"},
+			},
+		},
+	}
+
+	tests := []struct {
+		msg          string
+		failuresOnly bool
+		exp          string
+	}{
+		{
+			msg: "all results",
+			exp: `filename,kind,status,error
+deployment.yaml,Deployment,valid,
+service.yaml,Service,invalid,error: i am a error
+service.yaml,Service,invalid,"error: i am ""another"", error"
+crd.yaml,SealedSecret,skipped,
+pod.yaml,Pod,invalid,error: container-names: Nginx_Bad is not a valid DNS label
+pod.yaml,Pod,invalid,warning: labels: missing labels
+`,
+		},
+		{
+			msg:          "failures only",
+			failuresOnly: true,
+			exp: `filename,kind,status,error
+service.yaml,Service,invalid,error: i am a error
+service.yaml,Service,invalid,"error: i am ""another"", error"
+crd.yaml,SealedSecret,skipped,
+pod.yaml,Pod,invalid,error: container-names: Nginx_Bad is not a valid DNS label
+pod.yaml,Pod,invalid,warning: labels: missing labels
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			s := newCSVOutputManager(buf, tt.failuresOnly, false)
+			for _, r := range results {
+				assert.NoError(t, s.Put(r))
+			}
+			assert.NoError(t, s.Flush())
+			assert.Equal(t, tt.exp, buf.String())
+		})
+	}
+
+	// the header is written even when there are no results
+	buf := new(bytes.Buffer)
+	assert.NoError(t, newCSVOutputManager(buf, false, false).Flush())
+	assert.Equal(t, "filename,kind,status,error\n", buf.String())
+}
+
+func Test_markdownOutputManager(t *testing.T) {
+	results := []ValidationResult{
+		{
+			FileName:               "deployment.yaml",
+			Kind:                   "Deployment",
+			ValidatedAgainstSchema: true,
+		},
+		{
+			FileName:               "service.yaml",
+			Kind:                   "Service",
+			ValidatedAgainstSchema: true,
+			Errors: newResultErrors([]string{
+				"i am a error",
+				"i am a | error",
+			}),
+		},
+		{
+			FileName: "crd.yaml",
+			Kind:     "SealedSecret",
+		},
+		{
+			FileName:               "pod.yaml",
+			Kind:                   "Pod",
+			ValidatedAgainstSchema: true,
+			Findings: []Finding{
+				{CheckID: "container-names", Severity: SeverityError, Message: "Nginx_Bad is not a valid DNS label"},
+				{CheckID: "labels", Severity: SeverityWarning, Message: "missing labels"},
+			},
+		},
+	}
+
+	tests := []struct {
+		msg          string
+		failuresOnly bool
+		exp          string
+	}{
+		{
+			msg: "all results",
+			exp: `| File | Kind | Status | Errors |
+| --- | --- | --- | --- |
+| deployment.yaml | Deployment | ✅ valid |  |
+| service.yaml | Service | ❌ invalid | error: i am a error<br>error: i am a \| error |
+| crd.yaml | SealedSecret | ⚠️ skipped |  |
+| pod.yaml | Pod | ❌ invalid | error: container-names: Nginx_Bad is not a valid DNS label<br>warning: labels: missing labels |
+`,
+		},
+		{
+			msg:          "failures only",
+			failuresOnly: true,
+			exp: `| File | Kind | Status | Errors |
+| --- | --- | --- | --- |
+| service.yaml | Service | ❌ invalid | error: i am a error<br>error: i am a \| error |
+| crd.yaml | SealedSecret | ⚠️ skipped |  |
+| pod.yaml | Pod | ❌ invalid | error: container-names: Nginx_Bad is not a valid DNS label<br>warning: labels: missing labels |
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			s := newMarkdownOutputManager(log.New(buf, "", 0), tt.failuresOnly, false)
+			for _, r := range results {
+				assert.NoError(t, s.Put(r))
+			}
+			assert.NoError(t, s.Flush())
+			assert.Equal(t, tt.exp, buf.String())
+		})
+	}
+}
+
+func Test_templateOutputManager(t *testing.T) {
+	buf := new(bytes.Buffer)
+	s, err := newTemplateOutputManager(log.New(buf, "", 0), `{{.Status}} {{.FileName}} {{.Kind}}/{{.QualifiedName}}{{range .Errors}} [{{.}}]{{end}}`, true, false)
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Put(ValidationResult{
+		FileName:               "deployment.yaml",
+		Kind:                   "Deployment",
+		ValidatedAgainstSchema: true,
+	}))
+	assert.Equal(t, "", buf.String())
+
+	assert.NoError(t, s.Put(ValidationResult{
+		FileName:               "service.yaml",
+		Kind:                   "Service",
+		ResourceName:           "web",
+		ResourceNamespace:      "default",
+		ValidatedAgainstSchema: true,
+		Errors:                 newResultErrors([]string{"i am a error", "i am another error"}),
+	}))
+	assert.NoError(t, s.Put(ValidationResult{
+		FileName: "crd.yaml",
+		Kind:     "SealedSecret",
+	}))
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, `invalid service.yaml Service/default.web [error: i am a error] [error: i am another error]
+skipped crd.yaml SealedSecret/unknown
+`, buf.String())
+
+	buf.Reset()
+	s, err = newTemplateOutputManager(log.New(buf, "", 0), `{{.FileName}}{{range .Findings}} [{{.Severity}} {{.}}]{{end}}`, true, false)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Put(ValidationResult{
+		FileName:               "pod.yaml",
+		Kind:                   "Pod",
+		ValidatedAgainstSchema: true,
+		Findings:               []Finding{{CheckID: "container-names", Severity: SeverityError, Message: "bad name"}},
+	}))
+	assert.Equal(t, "pod.yaml [error container-names: bad name]\n", buf.String())
+}
+
+func Test_templateOutputManager_errors(t *testing.T) {
+	_, err := GetOutputManagerWithTemplate("{{.FileName", new(bytes.Buffer), false, false)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Failed to parse output template: ")
+	}
+
+	s, err := newTemplateOutputManager(log.New(new(bytes.Buffer), "", 0), "{{.Missing}}", false, false)
+	assert.NoError(t, err)
+	err = s.Put(ValidationResult{FileName: "service.yaml"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Failed to execute output template for service.yaml: ")
+	}
+
+	_, err = GetOutputManager(outputTemplate, false, false)
+	assert.Error(t, err)
+}
+
+func Test_outputManagers_failuresOnly(t *testing.T) {
+	results := []ValidationResult{
+		{
+			FileName:               "deployment.yaml",
+			Kind:                   "Deployment",
+			ValidatedAgainstSchema: true,
+		},
+		{
+			FileName:               "service.yaml",
+			Kind:                   "Service",
+			ValidatedAgainstSchema: true,
+			Errors:                 newResultErrors([]string{"i am a error"}),
+		},
+		{
+			FileName: "crd.yaml",
+			Kind:     "SealedSecret",
+		},
+	}
+
+	tests := []struct {
+		msg string
+		new func(l *log.Logger) outputManager
+		exp string
+	}{
+		{
+			msg: "json",
+			new: func(l *log.Logger) outputManager { return newJSONOutputManager(l, true, false) },
+			exp: `[
+	{
+		"filename": "service.yaml",
+		"kind": "Service",
+		"status": "invalid",
+		"errors": [
+			{
+				"message": "error: i am a error"
+			}
+		]
+	},
+	{
+		"filename": "crd.yaml",
+		"kind": "SealedSecret",
+		"status": "skipped",
+		"skipReason": "no_schema_found",
+		"errors": []
+	}
+]
+`,
+		},
+		{
+			msg: "tap",
+			new: func(l *log.Logger) outputManager { return newTAPOutputManager(l, true, false) },
+			exp: `TAP version 13
+1..2
+not ok 1 - service.yaml (Service)
+  ---
+  message: "error: i am a error"
+  severity: "fail"
+  kind: "Service"
+  filename: "service.yaml"
+  ...
+ok 2 - crd.yaml (SealedSecret) # SKIP no_schema_found
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			s := tt.new(log.New(buf, "", 0))
+			for _, r := range results {
+				assert.NoError(t, s.Put(r))
+			}
+			assert.NoError(t, s.Flush())
+			assert.Equal(t, tt.exp, buf.String())
+		})
+	}
+}
+
+func Test_jsonOutputManager_failuresOnlyFindings(t *testing.T) {
+	// valid results with findings are still reported with FailuresOnly
+	buf := new(bytes.Buffer)
+	s := newJSONOutputManager(log.New(buf, "", 0), true, false)
+	assert.NoError(t, s.Put(ValidationResult{
+		FileName:               "pod.yaml",
+		Kind:                   "Pod",
+		ValidatedAgainstSchema: true,
+		Findings:               []Finding{{CheckID: "container-names", Severity: SeverityError, Message: "bad name"}},
+	}))
+	assert.NoError(t, s.Flush())
+	assert.Contains(t, buf.String(), `"check": "container-names"`)
+}
+
+func Test_outputManagers_skipWarnings(t *testing.T) {
+	results := []ValidationResult{
+		{
+			FileName:               "service.yaml",
+			Kind:                   "Service",
+			ValidatedAgainstSchema: true,
+			Errors:                 newResultErrors([]string{"i am a error"}),
+		},
+		{
+			FileName: "crd.yaml",
+			Kind:     "SealedSecret",
+		},
+		{
+			FileName: "blank.yaml",
+		},
+	}
+
+	tests := []struct {
+		outFmt string
+		exp    string
+	}{
+		{
+			outFmt: outputSTD,
+			exp: `WARN - service.yaml contains an invalid Service (unknown) - error: i am a error
+Summary: 0 valid, 1 invalid, 2 skipped across 3 files
+`,
+		},
+		{
+			outFmt: outputTAP,
+			exp: `TAP version 13
+1..1
+not ok 1 - service.yaml (Service)
+  ---
+  message: "error: i am a error"
+  severity: "fail"
+  kind: "Service"
+  filename: "service.yaml"
+  ...
+`,
+		},
+		{
+			outFmt: outputJSONL,
+			exp: `{"filename":"service.yaml","kind":"Service","status":"invalid","errors":[{"message":"error: i am a error"}]}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.outFmt, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			s, err := GetOutputManagerWithWriter(tt.outFmt, buf, false, true)
+			if !assert.NoError(t, err) {
+				return
+			}
+			for _, r := range results {
+				assert.NoError(t, s.Put(r))
+			}
+			assert.NoError(t, s.Flush())
+			assert.Equal(t, tt.exp, buf.String())
+		})
+	}
+
+	// skipped results with findings are still reported
+	buf := new(bytes.Buffer)
+	s := newJSONLOutputManager(log.New(buf, "", 0), false, true)
+	assert.NoError(t, s.Put(ValidationResult{
+		FileName: "crd.yaml",
+		Kind:     "SealedSecret",
+		Findings: []Finding{{CheckID: "labels", Severity: SeverityWarning, Message: "missing labels"}},
+	}))
+	assert.Contains(t, buf.String(), `"filename":"crd.yaml"`)
+}
+
+func Test_outputManagers_hasErrors(t *testing.T) {
+	valid := ValidationResult{
+		FileName:               "deployment.yaml",
+		Kind:                   "Deployment",
+		ValidatedAgainstSchema: true,
+	}
+	invalid := ValidationResult{
+		FileName:               "service.yaml",
+		Kind:                   "Service",
+		ValidatedAgainstSchema: true,
+		Errors:                 newResultErrors([]string{"i am a error"}),
+	}
+	warning := ValidationResult{
+		FileName:               "pod.yaml",
+		Kind:                   "Pod",
+		ValidatedAgainstSchema: true,
+		Findings:               []Finding{{CheckID: "labels", Severity: SeverityWarning, Message: "missing labels"}},
+	}
+	failing := ValidationResult{
+		FileName:               "pod.yaml",
+		Kind:                   "Pod",
+		ValidatedAgainstSchema: true,
+		Findings:               []Finding{{CheckID: "container-names", Severity: SeverityError, Message: "bad name"}},
+	}
+
+	for _, outFmt := range validOutputs() {
+		if outFmt == outputTemplate {
+			continue
+		}
+		t.Run(outFmt, func(t *testing.T) {
+			// invalid results count even when valid ones are left out
+			m, err := GetOutputManagerWithWriter(outFmt, new(bytes.Buffer), true, true)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NoError(t, m.Put(valid))
+			assert.False(t, m.HasErrors())
+			assert.NoError(t, m.Put(invalid))
+			assert.NoError(t, m.Put(valid))
+			assert.True(t, m.HasErrors())
+
+			// findings with error severity fail validation too
+			m, err = GetOutputManagerWithWriter(outFmt, new(bytes.Buffer), true, true)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NoError(t, m.Put(warning))
+			assert.False(t, m.HasErrors())
+			assert.NoError(t, m.Put(failing))
+			assert.True(t, m.HasErrors())
+		})
+	}
+
+	tmpl, err := newTemplateOutputManager(log.New(new(bytes.Buffer), "", 0), "{{.FileName}}", false, false)
+	assert.NoError(t, err)
+	multi := newMultiOutputManager(newJSONOutputManager(log.New(new(bytes.Buffer), "", 0), false, false), tmpl)
+	assert.NoError(t, multi.Put(valid))
+	assert.False(t, multi.HasErrors())
+	assert.NoError(t, multi.Put(invalid))
+	assert.True(t, multi.HasErrors())
+	assert.True(t, tmpl.HasErrors())
+}
+
+func Test_GetOutputManagerWithWriter(t *testing.T) {
+	tests := []struct {
+		outFmt string
+		exp    string
+	}{
+		{outputSTD, "PASS - deployment.yaml contains a valid Deployment (web)\n"},
+		{outputJSON, `"filename": "deployment.yaml"`},
+		{outputTAP, "TAP version 13\n1..1\nok 1 - deployment.yaml (Deployment)\n"},
+		{outputJUnit, `<testcase name="web" classname="deployment.yaml">`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.outFmt, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			m, err := GetOutputManagerWithWriter(tt.outFmt, buf, false, false)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NoError(t, m.Put(ValidationResult{
+				FileName:               "deployment.yaml",
+				Kind:                   "Deployment",
+				ResourceName:           "web",
+				ValidatedAgainstSchema: true,
+			}))
+			assert.NoError(t, m.Flush())
+			assert.Contains(t, buf.String(), tt.exp)
+		})
+	}
+
+	buf := new(bytes.Buffer)
+	m, err := GetOutputManagerWithTemplate("{{.Status}} {{.FileName}}", buf, false, false)
+	if assert.NoError(t, err) {
+		assert.NoError(t, m.Put(ValidationResult{
+			FileName:               "deployment.yaml",
+			Kind:                   "Deployment",
+			ValidatedAgainstSchema: true,
+		}))
+		assert.NoError(t, m.Flush())
+		assert.Equal(t, "valid deployment.yaml\n", buf.String())
+	}
+}
+
+func Test_STDOutputManager_groupByFile(t *testing.T) {
+	results := []ValidationResult{
+		{
+			FileName:               "b.yaml",
+			Kind:                   "Deployment",
+			ResourceName:           "web",
+			ValidatedAgainstSchema: true,
+		},
+		{
+			FileName:               "a.yaml",
+			Kind:                   "Service",
+			ResourceName:           "web",
+			ValidatedAgainstSchema: true,
+			Errors:                 newResultErrors([]string{"i am a error"}),
+		},
+		{
+			FileName:               "b.yaml",
+			Kind:                   "Service",
+			ResourceName:           "web",
+			ValidatedAgainstSchema: true,
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	s := newSTDOutputManager(buf, false, false)
+	s.GroupByFile = true
+	for _, r := range results {
+		assert.NoError(t, s.Put(r))
+	}
+	// nothing is printed until Flush
+	assert.Equal(t, "", buf.String())
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, `=== b.yaml ===
+PASS - b.yaml contains a valid Deployment (web)
+PASS - b.yaml contains a valid Service (web)
+=== a.yaml ===
+WARN - a.yaml contains an invalid Service (web) - error: i am a error
+Summary: 2 valid, 1 invalid, 0 skipped across 2 files
+`, buf.String())
+
+	// files with nothing to report have no header
+	buf.Reset()
+	s = newSTDOutputManager(buf, true, false)
+	s.GroupByFile = true
+	for _, r := range results {
+		assert.NoError(t, s.Put(r))
+	}
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, `=== a.yaml ===
+WARN - a.yaml contains an invalid Service (web) - error: i am a error
+Summary: 2 valid, 1 invalid, 0 skipped across 2 files
+`, buf.String())
+}
+
+func Test_STDOutputManager_summary(t *testing.T) {
+	results := []ValidationResult{
+		{
+			FileName:               "deployment.yaml",
+			Kind:                   "Deployment",
+			ResourceName:           "web",
+			ValidatedAgainstSchema: true,
+		},
+		{
+			FileName:               "deployment.yaml",
+			Kind:                   "Service",
+			ResourceName:           "web",
+			ValidatedAgainstSchema: true,
+			Errors:                 newResultErrors([]string{"i am a error"}),
+		},
+		{
+			FileName:     "crd.yaml",
+			Kind:         "SealedSecret",
+			ResourceName: "token",
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	s := newSTDOutputManager(buf, true, false)
+	for _, r := range results {
+		assert.NoError(t, s.Put(r))
+	}
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, `WARN - deployment.yaml contains an invalid Service (web) - error: i am a error
+WARN - crd.yaml containing a SealedSecret (token) was not validated against a schema
+Summary: 1 valid, 1 invalid, 1 skipped across 2 files
+`, buf.String())
+
+	buf.Reset()
+	s = newSTDOutputManager(buf, false, false)
+	assert.NoError(t, s.Put(results[0]))
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, `PASS - deployment.yaml contains a valid Deployment (web)
+Summary: 1 valid, 0 invalid, 0 skipped across 1 file
+`, buf.String())
+}
+
+func Test_STDOutputManager_errorFindings(t *testing.T) {
+	results := []ValidationResult{
+		{
+			FileName:               "pod.yaml",
+			Kind:                   "Pod",
+			ResourceName:           "web",
+			ValidatedAgainstSchema: true,
+			Findings:               []Finding{{CheckID: "container-names", Severity: SeverityError, Message: "bad name"}},
+		},
+		{
+			FileName:               "deployment.yaml",
+			Kind:                   "Deployment",
+			ResourceName:           "web",
+			ValidatedAgainstSchema: true,
+			Findings:               []Finding{{CheckID: "labels", Severity: SeverityWarning, Message: "missing labels"}},
+		},
+	}
+
+	// a finding with error severity fails the resource, in the same way as
+	// the exit code
+	buf := new(bytes.Buffer)
+	s := newSTDOutputManager(buf, false, false)
+	for _, r := range results {
+		assert.NoError(t, s.Put(r))
+	}
+	assert.NoError(t, s.Flush())
+	assert.True(t, s.HasErrors())
+	assert.Equal(t, `WARN - pod.yaml contains an invalid Pod (web) - container-names: bad name
+PASS - deployment.yaml contains a valid Deployment (web)
+WARN - deployment.yaml contains a Deployment (web) - labels: missing labels
+Summary: 1 valid, 1 invalid, 0 skipped across 2 files
+`, buf.String())
+
+	assert.Equal(t, status(statusInvalid), newDataEvalResult(results[0]).Status)
+	assert.Equal(t, status(statusValid), newDataEvalResult(results[1]).Status)
+}
+
+func Test_summary_byNamespace(t *testing.T) {
+	results := []ValidationResult{
+		{
+			FileName:               "deployment.yaml",
+			Kind:                   "Deployment",
+			ResourceName:           "web",
+			ResourceNamespace:      "prod",
+			ValidatedAgainstSchema: true,
+		},
+		{
+			FileName:               "deployment.yaml",
+			Kind:                   "Service",
+			ResourceName:           "web",
+			ValidatedAgainstSchema: true,
+			Errors:                 newResultErrors([]string{"i am a error"}),
+		},
+		{
+			FileName:               "namespace.yaml",
+			Kind:                   "Namespace",
+			ResourceName:           "prod",
+			ValidatedAgainstSchema: true,
+		},
+		{
+			FileName: "blank.yaml",
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	s := newSTDOutputManager(buf, true, true)
+	s.ByNamespace = true
+	for _, r := range results {
+		assert.NoError(t, s.Put(r))
+	}
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, `WARN - deployment.yaml contains an invalid Service (web) - error: i am a error
+Summary: 2 valid, 1 invalid, 1 skipped across 3 files
+  default: 0 valid, 1 invalid, 0 skipped
+  prod: 1 valid, 0 invalid, 0 skipped
+  (cluster): 1 valid, 0 invalid, 0 skipped
+`, buf.String())
+
+	// the JSON summary counts results left out of the output too
+	buf.Reset()
+	j := newJSONOutputManager(log.New(buf, "", 0), true, true)
+	j.summary = newSummary("default")
+	for _, r := range results {
+		assert.NoError(t, j.Put(r))
+	}
+	assert.NoError(t, j.Flush())
+	assert.Equal(t, `{
+	"summary": {
+		"valid": 2,
+		"invalid": 1,
+		"skipped": 1,
+		"namespaces": {
+			"(cluster)": {
+				"valid": 1,
+				"invalid": 0,
+				"skipped": 0
+			},
+			"default": {
+				"valid": 0,
+				"invalid": 1,
+				"skipped": 0
+			},
+			"prod": {
+				"valid": 1,
+				"invalid": 0,
+				"skipped": 0
+			}
+		}
+	},
+	"results": [
+		{
+			"filename": "deployment.yaml",
+			"kind": "Service",
+			"status": "invalid",
+			"errors": [
+				{
+					"message": "error: i am a error"
+				}
+			]
+		}
+	]
+}
+`, buf.String())
+}
+
+func Test_outputManagers_concurrentPut(t *testing.T) {
+	const goroutines = 50
+
+	tests := []struct {
+		outFmt string
+		count  func(out string) int
+	}{
+		{outputSTD, func(out string) int { return strings.Count(out, "PASS - ") }},
+		{outputJSON, func(out string) int { return strings.Count(out, `"filename"`) }},
+		{outputTAP, func(out string) int { return strings.Count(out, "\nok ") }},
+		{outputJUnit, func(out string) int { return strings.Count(out, "<testcase ") }},
+		{outputJSONL, func(out string) int { return strings.Count(out, "\n") }},
+		{outputCSV, func(out string) int { return strings.Count(out, ",Deployment,valid,") }},
+		{outputMarkdown, func(out string) int { return strings.Count(out, "| Deployment | ✅ valid |") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.outFmt, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			m, err := GetOutputManagerWithWriter(tt.outFmt, buf, false, false)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			var wg sync.WaitGroup
+			for i := 0; i < goroutines; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					assert.NoError(t, m.Put(ValidationResult{
+						FileName:               fmt.Sprintf("deployment-%d.yaml", i),
+						Kind:                   "Deployment",
+						ResourceName:           "web",
+						ValidatedAgainstSchema: true,
+					}))
+				}(i)
+			}
+			wg.Wait()
+			assert.NoError(t, m.Flush())
+			assert.Equal(t, goroutines, tt.count(buf.String()))
+		})
+	}
+}
+
+func Test_GetOutputManager_unknownFormat(t *testing.T) {
+	_, err := GetOutputManager("jsn", false, false)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Unknown output format 'jsn', valid formats are: stdout, json, tap, junit, github, jsonl, template, csv, markdown", err.Error())
+	}
+
+	m, err := GetOutputManager("", false, false)
+	assert.NoError(t, err)
+	assert.IsType(t, &STDOutputManager{}, m)
+}
+
+func Test_GetOutputManagerFromConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := NewDefaultConfig()
+	config.OutputFormat = outputTAP
+	config.OutputJSONFile = filepath.Join(dir, "report.json")
+	console := new(bytes.Buffer)
+	m, err := GetOutputManagerFromConfigWithWriter(config, console)
+	if assert.NoError(t, err) {
+		assert.NoError(t, m.Put(ValidationResult{
+			FileName:               "deployment.yaml",
+			Kind:                   "Deployment",
+			ValidatedAgainstSchema: true,
+		}))
+		assert.NoError(t, m.Flush())
+		assert.Equal(t, "TAP version 13\n1..1\nok 1 - deployment.yaml (Deployment)\n", console.String())
+
+		report, err := ioutil.ReadFile(config.OutputJSONFile)
+		assert.NoError(t, err)
+		assert.Contains(t, string(report), `"filename": "deployment.yaml"`)
+	}
+
+	config.OutputFormat = outputJSON
+	_, err = GetOutputManagerFromConfigWithWriter(config, new(bytes.Buffer))
+	assert.Error(t, err)
+
+	// run metadata needs an output which can include it
+	config = NewDefaultConfig()
+	config.OutputFormat = outputTAP
+	config.RunID = "build-42"
+	_, err = GetOutputManagerFromConfigWithWriter(config, new(bytes.Buffer))
+	assert.Error(t, err)
+	config.OutputFormat = outputJUnit
+	console.Reset()
+	m, err = GetOutputManagerFromConfigWithWriter(config, console)
+	if assert.NoError(t, err) {
+		assert.NoError(t, m.Flush())
+		assert.Contains(t, console.String(), `<property name="runId" value="build-42"></property>`)
+	}
+
+	// --junit-per-error is rejected rather than ignored by other formats
+	config = NewDefaultConfig()
+	config.OutputFormat = outputJSON
+	config.JUnitPerError = true
+	_, err = GetOutputManagerFromConfigWithWriter(config, new(bytes.Buffer))
+	assert.EqualError(t, err, "--junit-per-error requires --output junit")
+	config.OutputFormat = outputJUnit
+	m, err = GetOutputManagerFromConfigWithWriter(config, new(bytes.Buffer))
+	if assert.NoError(t, err) {
+		assert.True(t, m.(*junitOutputManager).PerError)
+	}
+
+	config = NewDefaultConfig()
+	config.SummaryByNamespace = true
+	config.DefaultNamespace = "team"
+	console.Reset()
+	m, err = GetOutputManagerFromConfigWithWriter(config, console)
+	if assert.NoError(t, err) {
+		assert.NoError(t, m.Put(ValidationResult{
+			FileName:               "deployment.yaml",
+			Kind:                   "Deployment",
+			ValidatedAgainstSchema: true,
+		}))
+		assert.NoError(t, m.Flush())
+		assert.Contains(t, console.String(), "  team: 1 valid, 0 invalid, 0 skipped\n")
+	}
+
+	// --group-by-file only applies to the stdout output
+	config = NewDefaultConfig()
+	config.GroupByFile = true
+	_, err = GetOutputManagerFromConfigWithWriter(config, new(bytes.Buffer))
+	assert.NoError(t, err)
+	for _, outFmt := range []string{outputJSON, outputTAP, outputCSV} {
+		config.OutputFormat = outFmt
+		_, err = GetOutputManagerFromConfigWithWriter(config, new(bytes.Buffer))
+		assert.EqualError(t, err, "--group-by-file requires --output stdout", outFmt)
+	}
+
+	config = NewDefaultConfig()
+	config.SummaryByNamespace = true
+	config.DefaultNamespace = "team"
+
+	// formats without a summary reject --summary-by-namespace, unless it
+	// goes to a JSON report
+	for _, outFmt := range []string{outputTAP, outputJUnit, outputCSV} {
+		config.OutputFormat = outFmt
+		_, err = GetOutputManagerFromConfigWithWriter(config, new(bytes.Buffer))
+		assert.Error(t, err, outFmt)
+	}
+	config.OutputFormat = outputJSON
+	config.OutputDir = dir
+	_, err = GetOutputManagerFromConfigWithWriter(config, new(bytes.Buffer))
+	assert.Error(t, err)
+	config.OutputDir = ""
+	config.OutputFormat = outputTAP
+	config.OutputJSONFile = filepath.Join(dir, "summary.json")
+	_, err = GetOutputManagerFromConfigWithWriter(config, new(bytes.Buffer))
+	assert.NoError(t, err)
+}
+
+func Test_jsonOutputManager_runMetadata(t *testing.T) {
+	buf := new(bytes.Buffer)
+	s := newJSONOutputManager(log.New(buf, "", 0), false, false)
+	s.run = &runMetadata{
+		RunID:     "build-42",
+		Timestamp: "2020-01-02T03:04:05Z",
+	}
+	assert.NoError(t, s.Put(ValidationResult{
+		FileName:               "deployment.yaml",
+		Kind:                   "deployment",
+		ValidatedAgainstSchema: true,
+	}))
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, `{
+	"runId": "build-42",
+	"timestamp": "2020-01-02T03:04:05Z",
+	"results": [
+		{
+			"filename": "deployment.yaml",
+			"kind": "deployment",
+			"status": "valid",
+			"errors": []
+		}
+	]
+}
+`, buf.String())
+
+	// with no results, the results are an empty array rather than null
+	buf.Reset()
+	s = newJSONOutputManager(log.New(buf, "", 0), true, false)
+	s.run = &runMetadata{
+		RunID:     "build-42",
+		Timestamp: "2020-01-02T03:04:05Z",
+	}
+	assert.NoError(t, s.Put(ValidationResult{
+		FileName:               "deployment.yaml",
+		Kind:                   "deployment",
+		ValidatedAgainstSchema: true,
+	}))
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, `{
+	"runId": "build-42",
+	"timestamp": "2020-01-02T03:04:05Z",
+	"results": []
+}
+`, buf.String())
+
+	run, err := newRunMetadata("")
+	if assert.NoError(t, err) {
+		assert.Len(t, run.RunID, 32)
+		assert.NotEmpty(t, run.Timestamp)
+	}
+}
+
+func Test_dirOutputManager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, err = newDirOutputManager(dir, outputSTD, false, false, nil)
+	assert.Error(t, err)
+
+	m, err := newDirOutputManager(dir, outputTAP, false, false, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, fileName := range []string{"manifests/a.yaml", "manifests/a.yaml", "/tmp/b.yaml"} {
+		assert.NoError(t, m.Put(ValidationResult{
+			FileName:               fileName,
+			Kind:                   "Deployment",
+			ValidatedAgainstSchema: true,
+		}))
+	}
+	assert.NoError(t, m.Flush())
+
+	a, err := ioutil.ReadFile(filepath.Join(dir, "manifests", "a.yaml.tap"))
+	assert.NoError(t, err)
+	assert.Equal(t, "TAP version 13\n1..2\nok 1 - manifests/a.yaml (Deployment)\nok 2 - manifests/a.yaml (Deployment)\n", string(a))
+	b, err := ioutil.ReadFile(filepath.Join(dir, "tmp", "b.yaml.tap"))
+	assert.NoError(t, err)
+	assert.Equal(t, "TAP version 13\n1..1\nok 1 - /tmp/b.yaml (Deployment)\n", string(b))
+
+	// names which map to the same output file share it, rather than the
+	// last to be flushed overwriting the others
+	m, err = newDirOutputManager(dir, outputTAP, false, false, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, m.Put(ValidationResult{
+		FileName:               "c.yaml",
+		Kind:                   "Deployment",
+		ValidatedAgainstSchema: true,
+		Errors:                 newResultErrors([]string{"i am a error"}),
+	}))
+	assert.NoError(t, m.Put(ValidationResult{
+		FileName:               "./c.yaml",
+		Kind:                   "Deployment",
+		ValidatedAgainstSchema: true,
+	}))
+	assert.NoError(t, m.Flush())
+	c, err := ioutil.ReadFile(filepath.Join(dir, "c.yaml.tap"))
+	assert.NoError(t, err)
+	assert.Equal(t, `TAP version 13
+1..2
+not ok 1 - c.yaml (Deployment)
+  ---
+  message: "error: i am a error"
+  severity: "fail"
+  kind: "Deployment"
+  filename: "c.yaml"
+  ...
+ok 2 - ./c.yaml (Deployment)
+`, string(c))
+}
+
+func Test_outputDirPath(t *testing.T) {
+	tests := []struct {
+		fileName string
+		exp      string
+	}{
+		{"fixtures/valid.yaml", filepath.Join("out", "fixtures", "valid.yaml.json")},
+		{"./valid.yaml", filepath.Join("out", "valid.yaml.json")},
+		{"/abs/valid.yaml", filepath.Join("out", "abs", "valid.yaml.json")},
+		{"../../valid.yaml", filepath.Join("out", "valid.yaml.json")},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.exp, outputDirPath("out", tt.fileName, outputJSON))
+	}
+}
+
+func Test_UseColor(t *testing.T) {
+	if noColor, ok := os.LookupEnv("NO_COLOR"); ok {
+		defer os.Setenv("NO_COLOR", noColor)
+	} else {
+		defer os.Unsetenv("NO_COLOR")
+	}
+
+	tests := []struct {
+		mode    string
+		noColor string
+		exp     bool
+	}{
+		{ColorAlways, "", true},
+		{ColorAlways, "1", true},
+		{ColorNever, "", false},
+		// a buffer is never a terminal
+		{ColorAuto, "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		os.Setenv("NO_COLOR", tt.noColor)
+		color, err := UseColor(tt.mode, new(bytes.Buffer))
+		assert.NoError(t, err)
+		assert.Equal(t, tt.exp, color, "mode %q with NO_COLOR=%q", tt.mode, tt.noColor)
+	}
+
+	_, err := UseColor("sometimes", new(bytes.Buffer))
+	if assert.Error(t, err) {
+		assert.Equal(t, "Unknown color mode 'sometimes', valid modes are: auto, always, never", err.Error())
+	}
+}
+
+func Test_STDOutputManager_color(t *testing.T) {
+	result := ValidationResult{
+		FileName:               "deployment.yaml",
+		Kind:                   "Deployment",
+		ResourceName:           "web",
+		ValidatedAgainstSchema: true,
+	}
+
+	// output to a buffer is not colored by default
+	buf := new(bytes.Buffer)
+	s := newSTDOutputManager(buf, false, false)
+	assert.NoError(t, s.Put(result))
+	assert.Equal(t, "PASS - deployment.yaml contains a valid Deployment (web)\n", buf.String())
+
+	buf.Reset()
+	s.Color = true
+	assert.NoError(t, s.Put(result))
+	assert.Equal(t, "\x1b[32mPASS\x1b[0m - deployment.yaml contains a valid Deployment (web)\n", buf.String())
+}
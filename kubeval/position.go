@@ -0,0 +1,138 @@
+package kubeval
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// A PositionedError is a schema error which also records the line and
+// column in the input file of the node which failed validation. Errors in
+// ValidationResult.Errors implement it when the position is known
+type PositionedError interface {
+	gojsonschema.ResultError
+	Line() int
+	Column() int
+}
+
+// positionedResultError wraps a gojsonschema.ResultError with the position
+// of the offending node
+type positionedResultError struct {
+	gojsonschema.ResultError
+	line   int
+	column int
+}
+
+// Line returns the line of the offending node, starting at 1
+func (p positionedResultError) Line() int {
+	return p.line
+}
+
+// Column returns the column of the offending node, starting at 1
+func (p positionedResultError) Column() int {
+	return p.column
+}
+
+// errorPosition returns the line and column of e, if they are known
+func errorPosition(e gojsonschema.ResultError) (int, int, bool) {
+	if p, ok := e.(PositionedError); ok && p.Line() > 0 {
+		return p.Line(), p.Column(), true
+	}
+	return 0, 0, false
+}
+
+// source is YAML from the input which one or more documents were decoded
+// from. It is only parsed again, keeping positions, when a resource decoded
+// from it has errors to report
+type source struct {
+	data []byte
+	// line is the line of the input on which data starts
+	line int
+
+	root   *yaml.Node
+	parsed bool
+}
+
+// node returns the root node of the source, or nil if it can't be parsed
+func (s *source) node() *yaml.Node {
+	if !s.parsed {
+		s.parsed = true
+		var doc yaml.Node
+		if err := yaml.Unmarshal(s.data, &doc); err == nil && len(doc.Content) > 0 {
+			s.root = doc.Content[0]
+		}
+	}
+	return s.root
+}
+
+// errorPath returns the keys and indexes leading from the root of a resource
+// to the node an error refers to
+func errorPath(e gojsonschema.ResultError) []string {
+	if e.Context() == nil {
+		return nil
+	}
+	// keys may contain dots, so the context is joined with a delimiter which
+	// can't appear in YAML, and the leading (root) is dropped
+	path := strings.Split(e.Context().String("\x00"), "\x00")[1:]
+	if e.Type() == "additional_property_not_allowed" {
+		if property, ok := e.Details()["property"].(string); ok {
+			path = append(path, property)
+		}
+	}
+	return path
+}
+
+// lookupNode returns the node at path below node or, when part of the path
+// doesn't exist, such as for a missing required property, the deepest node
+// along it. Mapping entries are found at their key
+func lookupNode(node *yaml.Node, path []string) *yaml.Node {
+	found := node
+	for _, key := range path {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		var at, next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					at, next = node.Content[i], node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(node.Content) {
+				at, next = node.Content[i], node.Content[i]
+			}
+		}
+		if next == nil {
+			return found
+		}
+		found, node = at, next
+	}
+	return found
+}
+
+// positionErrors wraps each of errs with the position of the node it refers
+// to in the input which doc was decoded from
+func positionErrors(errs []gojsonschema.ResultError, doc document) []gojsonschema.ResultError {
+	if len(errs) == 0 || doc.src == nil {
+		return errs
+	}
+	root := doc.src.node()
+	if root == nil {
+		return errs
+	}
+	resource := lookupNode(root, doc.path)
+	for i, e := range errs {
+		n := lookupNode(resource, errorPath(e))
+		errs[i] = positionedResultError{
+			ResultError: e,
+			line:        doc.src.line + n.Line - 1,
+			column:      n.Column,
+		}
+	}
+	return errs
+}
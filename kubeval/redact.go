@@ -0,0 +1,72 @@
+package kubeval
+
+import (
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// redactedValue replaces sensitive values in validation errors
+const redactedValue = "[REDACTED]"
+
+// redactedDetails are the error details which can hold the offending value
+var redactedDetails = []string{"given", "value"}
+
+// redactedResultError wraps a gojsonschema.ResultError so that the value
+// which failed validation never appears in its output
+type redactedResultError struct {
+	gojsonschema.ResultError
+}
+
+// Value returns a placeholder in place of the offending value
+func (r redactedResultError) Value() interface{} {
+	return redactedValue
+}
+
+// Details returns the error details with any which can hold the offending
+// value masked. The description is left as it is, as gojsonschema's messages
+// don't include the value
+func (r redactedResultError) Details() gojsonschema.ErrorDetails {
+	details := gojsonschema.ErrorDetails{}
+	for k, v := range r.ResultError.Details() {
+		if in(redactedDetails, k) {
+			v = redactedValue
+		}
+		details[k] = v
+	}
+	return details
+}
+
+// shouldRedact returns whether errors and findings for field of the given
+// kind should have their values masked
+func shouldRedact(kind, field string, config *Config) bool {
+	if in(config.RedactKinds, kind) {
+		return true
+	}
+	for _, path := range config.RedactFields {
+		if field == path || strings.HasPrefix(field, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// redactErrors masks the values of any errors covered by the redaction settings
+func redactErrors(errs []gojsonschema.ResultError, kind string, config *Config) []gojsonschema.ResultError {
+	for i, e := range errs {
+		if shouldRedact(kind, e.Field(), config) {
+			errs[i] = redactedResultError{e}
+		}
+	}
+	return errs
+}
+
+// redactFinding masks the message of a finding covered by the redaction
+// settings. Check messages quote the values they refer to, so only the check
+// and path are kept
+func redactFinding(f Finding, kind string, config *Config) Finding {
+	if shouldRedact(kind, f.Path, config) {
+		f.Message = redactedValue
+	}
+	return f
+}
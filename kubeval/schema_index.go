@@ -0,0 +1,136 @@
+package kubeval
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// A schemaIndex maps the apiVersion/kind of a resource, as returned by
+// ValidationResult.VersionKind, to the location of its schema. Locations
+// may be absolute URLs, or paths relative to the index itself.
+type schemaIndex struct {
+	location string
+	schemas  map[string]string
+
+	// err is the error the index failed to load with, so that a missing or
+	// invalid index is only read once
+	err error
+}
+
+// isRemote returns whether a location should be fetched over HTTP
+func isRemote(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// validateSchemaIndexOptions returns an error if config combines a schema
+// index with options which select schemas by convention, as the entries of
+// an index are used as they are. Strict schemas, OpenShift schemas and
+// schemas for another version all need an index of their own
+func validateSchemaIndexOptions(config *Config) error {
+	if config.SchemaIndex == "" {
+		return nil
+	}
+	if config.Strict {
+		return fmt.Errorf("--strict cannot be combined with --schema-index, point the index at strict schemas instead")
+	}
+	if config.OpenShift {
+		return fmt.Errorf("--openshift cannot be combined with --schema-index, point the index at OpenShift schemas instead")
+	}
+	if config.KubernetesVersion != "" && config.KubernetesVersion != "master" {
+		return fmt.Errorf("--kubernetes-version cannot be combined with --schema-index, point the index at the schemas for %s instead", config.KubernetesVersion)
+	}
+	return nil
+}
+
+// loadSchemaIndex reads and decodes the index at config.SchemaIndex, if
+// one is set and it has not already been loaded. The outcome is cached, so
+// an index which fails to load returns the same error without being read
+// again.
+func loadSchemaIndex(config *Config) error {
+	if err := validateSchemaIndexOptions(config); err != nil {
+		return err
+	}
+	if config.SchemaIndex == "" {
+		return nil
+	}
+	if config.schemaIndex == nil || config.schemaIndex.location != config.SchemaIndex {
+		schemas, err := readSchemaIndex(config.SchemaIndex)
+		config.schemaIndex = &schemaIndex{
+			location: config.SchemaIndex,
+			schemas:  schemas,
+			err:      err,
+		}
+	}
+	return config.schemaIndex.err
+}
+
+// readSchemaIndex reads and decodes the index at location
+func readSchemaIndex(location string) (map[string]string, error) {
+	var contents []byte
+	var err error
+	if isRemote(location) {
+		var resp *http.Response
+		resp, err = http.Get(location)
+		if err == nil {
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("Failed to read schema index %s: response status is %s", location, resp.Status)
+			}
+			contents, err = ioutil.ReadAll(resp.Body)
+		}
+	} else {
+		contents, err = ioutil.ReadFile(strings.TrimPrefix(location, "file://"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read schema index %s: %s", location, err.Error())
+	}
+
+	schemas := map[string]string{}
+	if err := yaml.Unmarshal(contents, &schemas); err != nil {
+		return nil, fmt.Errorf("Failed to decode schema index %s: %s", location, err.Error())
+	}
+	return schemas, nil
+}
+
+// lookup returns the schema reference for the resource, resolving relative
+// entries against the location of the index
+func (i *schemaIndex) lookup(resource *ValidationResult) (string, error) {
+	entry, ok := i.schemas[resource.VersionKind()]
+	if !ok || entry == "" {
+		return "", fmt.Errorf("No schema for %s found in schema index %s", resource.VersionKind(), i.location)
+	}
+	if strings.Contains(entry, "://") {
+		return entry, nil
+	}
+
+	if isRemote(i.location) {
+		base, err := url.Parse(i.location)
+		if err != nil {
+			return "", fmt.Errorf("Invalid schema index location %s: %s", i.location, err.Error())
+		}
+		base.Path = path.Join(path.Dir(base.Path), entry)
+		return base.String(), nil
+	}
+
+	indexPath, err := filepath.Abs(strings.TrimPrefix(i.location, "file://"))
+	if err != nil {
+		return "", err
+	}
+	schemaPath := entry
+	if !filepath.IsAbs(schemaPath) {
+		schemaPath = filepath.Join(filepath.Dir(indexPath), entry)
+	}
+	schemaPath = filepath.ToSlash(schemaPath)
+	if !strings.HasPrefix(schemaPath, "/") {
+		// Windows paths need an extra slash to form a valid file URL
+		schemaPath = "/" + schemaPath
+	}
+	return "file://" + schemaPath, nil
+}
@@ -0,0 +1,84 @@
+package kubeval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// errorTypes are the types of schema error reported by gojsonschema, as
+// returned by gojsonschema.ResultError.Type
+var errorTypes = []string{
+	"required",
+	"invalid_type",
+	"number_any_of",
+	"number_one_of",
+	"number_all_of",
+	"number_not",
+	"missing_dependency",
+	"internal",
+	"const",
+	"enum",
+	"array_no_additional_items",
+	"array_min_items",
+	"array_max_items",
+	"unique",
+	"contains",
+	"array_min_properties",
+	"array_max_properties",
+	"additional_property_not_allowed",
+	"invalid_property_pattern",
+	"invalid_property_name",
+	"string_gte",
+	"string_lte",
+	"pattern",
+	"format",
+	"multiple_of",
+	"number_gte",
+	"number_gt",
+	"number_lte",
+	"number_lt",
+	"condition_then",
+	"condition_else",
+}
+
+// validateErrorSeverities returns an error if config maps an unknown error
+// type, or maps an error type to an unknown severity
+func validateErrorSeverities(config *Config) error {
+	for errorType, severity := range config.ErrorSeverities {
+		if !in(errorTypes, errorType) {
+			return fmt.Errorf("Unknown error type '%s', valid error types are: %s", errorType, strings.Join(errorTypes, ", "))
+		}
+		switch Severity(severity) {
+		case SeverityInfo, SeverityWarning, SeverityError:
+		default:
+			return fmt.Errorf("Unknown severity '%s' for error type '%s', valid severities are: %s, %s, %s", severity, errorType, SeverityInfo, SeverityWarning, SeverityError)
+		}
+	}
+	return nil
+}
+
+// applyErrorSeverities splits schema errors into those which remain errors,
+// and findings for those whose severity has been lowered by config
+func applyErrorSeverities(errs []gojsonschema.ResultError, config *Config) ([]gojsonschema.ResultError, []Finding) {
+	if len(config.ErrorSeverities) == 0 {
+		return errs, nil
+	}
+	remaining := []gojsonschema.ResultError{}
+	var findings []Finding
+	for _, e := range errs {
+		severity, ok := config.ErrorSeverities[e.Type()]
+		if !ok || Severity(severity) == SeverityError {
+			remaining = append(remaining, e)
+			continue
+		}
+		findings = append(findings, Finding{
+			CheckID:  e.Type(),
+			Severity: Severity(severity),
+			Path:     e.Field(),
+			Message:  e.Description(),
+		})
+	}
+	return remaining, findings
+}
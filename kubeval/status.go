@@ -0,0 +1,79 @@
+package kubeval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+const (
+	// StrictStatusValidate validates status against the strict schema, in
+	// the same way as every other field
+	StrictStatusValidate = "validate"
+	// StrictStatusLenient permits properties under status which are not in
+	// the schema, while still reporting other errors such as invalid types
+	StrictStatusLenient = "lenient"
+	// StrictStatusStrip removes status before validating, noting that it did so
+	StrictStatusStrip = "strip"
+)
+
+// validateStrictStatus returns an error if config.StrictStatus is unknown
+func validateStrictStatus(config *Config) error {
+	switch config.StrictStatus {
+	case "", StrictStatusValidate, StrictStatusLenient, StrictStatusStrip:
+		return nil
+	}
+	return fmt.Errorf("Unknown strict status handling '%s', valid options are: %s, %s, %s", config.StrictStatus, StrictStatusValidate, StrictStatusLenient, StrictStatusStrip)
+}
+
+// stripStatus returns the body to validate against the schema, without
+// status if config asks for it to be stripped in strict mode, along with
+// a finding noting that status was stripped
+func stripStatus(body map[string]interface{}, config *Config) (map[string]interface{}, []Finding) {
+	if !config.Strict || config.StrictStatus != StrictStatusStrip {
+		return body, nil
+	}
+	if _, found := body["status"]; !found {
+		return body, nil
+	}
+	stripped := make(map[string]interface{}, len(body)-1)
+	for k, v := range body {
+		if k != "status" {
+			stripped[k] = v
+		}
+	}
+	return stripped, []Finding{{
+		CheckID:  "strict-status",
+		Severity: SeverityInfo,
+		Path:     "status",
+		Message:  "Removed before validating against the strict schema",
+	}}
+}
+
+// isStatusProperty returns whether an additional property error is for a
+// property of status, or for status itself
+func isStatusProperty(e gojsonschema.ResultError) bool {
+	context := e.Context().String()
+	if context == "(root).status" || strings.HasPrefix(context, "(root).status.") {
+		return true
+	}
+	return context == "(root)" && e.Details()["property"] == "status"
+}
+
+// filterStatusErrors drops errors for properties under status which are
+// not in the strict schema, if config asks for status to be validated
+// leniently in strict mode
+func filterStatusErrors(errs []gojsonschema.ResultError, config *Config) []gojsonschema.ResultError {
+	if !config.Strict || config.StrictStatus != StrictStatusLenient {
+		return errs
+	}
+	remaining := []gojsonschema.ResultError{}
+	for _, e := range errs {
+		if e.Type() == "additional_property_not_allowed" && isStatusProperty(e) {
+			continue
+		}
+		remaining = append(remaining, e)
+	}
+	return remaining
+}
@@ -0,0 +1,89 @@
+package kubeval
+
+import (
+	"fmt"
+	"sort"
+)
+
+// clusterNamespace is the namespace summary under which cluster-scoped
+// resources are counted
+const clusterNamespace = "(cluster)"
+
+// summaryCounts counts results by status
+type summaryCounts struct {
+	Valid   int `json:"valid"`
+	Invalid int `json:"invalid"`
+	Skipped int `json:"skipped"`
+}
+
+func (c *summaryCounts) add(s status) {
+	switch s {
+	case statusValid:
+		c.Valid++
+	case statusInvalid:
+		c.Invalid++
+	case statusSkipped:
+		c.Skipped++
+	}
+}
+
+// String returns the counts as they are printed in the stdout summary
+func (c summaryCounts) String() string {
+	return fmt.Sprintf("%d valid, %d invalid, %d skipped", c.Valid, c.Invalid, c.Skipped)
+}
+
+// summary counts results by status, in total and for each namespace.
+// Empty documents have no namespace, so they are only counted in the
+// totals. It is not safe for concurrent use
+type summary struct {
+	summaryCounts
+	Namespaces map[string]*summaryCounts `json:"namespaces"`
+
+	// defaultNamespace is the namespace of namespaced resources which do
+	// not set `metadata:namespace`
+	defaultNamespace string
+}
+
+func newSummary(defaultNamespace string) *summary {
+	return &summary{
+		Namespaces:       map[string]*summaryCounts{},
+		defaultNamespace: defaultNamespace,
+	}
+}
+
+func (s *summary) add(r ValidationResult) {
+	status := getStatus(r)
+	s.summaryCounts.add(status)
+	if r.Kind == "" {
+		return
+	}
+
+	namespace := r.ResourceNamespace
+	if in(clusterScopedKinds, r.Kind) {
+		namespace = clusterNamespace
+	} else if namespace == "" {
+		namespace = s.defaultNamespace
+	}
+	counts, ok := s.Namespaces[namespace]
+	if !ok {
+		counts = &summaryCounts{}
+		s.Namespaces[namespace] = counts
+	}
+	counts.add(status)
+}
+
+// namespaces returns the namespaces results were counted under, sorted by
+// name with cluster-scoped resources last
+func (s *summary) namespaces() []string {
+	var namespaces []string
+	for namespace := range s.Namespaces {
+		if namespace != clusterNamespace {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	if _, ok := s.Namespaces[clusterNamespace]; ok {
+		namespaces = append(namespaces, clusterNamespace)
+	}
+	return namespaces
+}
@@ -3,6 +3,7 @@ package kubeval
 import (
 	"bytes"
 	"fmt"
+	"regexp"
 	"runtime"
 	"strings"
 )
@@ -22,6 +23,34 @@ func getObject(body map[string]interface{}, key string) (map[string]interface{},
 	return typedValue, nil
 }
 
+func getObjectAt(body map[string]interface{}, path []string) (map[string]interface{}, error) {
+	obj := body
+	for i, key := range path {
+		typed, err := getObject(obj, key)
+		if err != nil {
+			return nil, fmt.Errorf("Expected object at key '%s'", strings.Join(path[:i+1], "."))
+		}
+		obj = typed
+	}
+	return obj, nil
+}
+
+// getObjects returns the objects in the list under key, skipping any
+// elements which are not objects
+func getObjects(body map[string]interface{}, key string) []map[string]interface{} {
+	list, ok := body[key].([]interface{})
+	if !ok {
+		return nil
+	}
+	objects := make([]map[string]interface{}, 0, len(list))
+	for _, element := range list {
+		if typed, ok := element.(map[string]interface{}); ok {
+			objects = append(objects, typed)
+		}
+	}
+	return objects
+}
+
 func getStringAt(body map[string]interface{}, path []string) (string, error) {
 	obj := body
 	visited := []string{}
@@ -63,6 +92,35 @@ func getString(body map[string]interface{}, key string) (string, error) {
 	return typedValue, nil
 }
 
+// getNumber returns the numeric value at key, and whether one was found
+func getNumber(body map[string]interface{}, key string) (float64, bool) {
+	switch value := body[key].(type) {
+	case float64:
+		return value, true
+	case int64:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	}
+	return 0, false
+}
+
+// quantityPattern matches a Kubernetes resource quantity, such as 128Mi,
+// 0.5, 250m or 1e3
+var quantityPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)(([KMGTPE]i)|[numkMGTPE]|[eE][+-]?\d+)?$`)
+
+// isQuantity returns whether value is a valid Kubernetes resource quantity,
+// which may be written either as a string or a number
+func isQuantity(value interface{}) bool {
+	switch typed := value.(type) {
+	case float64, int64, int:
+		return true
+	case string:
+		return quantityPattern.MatchString(typed)
+	}
+	return false
+}
+
 // detectLineBreak returns the relevant platform specific line ending
 func detectLineBreak(haystack []byte) string {
 	windowsLineEnding := bytes.Contains(haystack, []byte("\r\n"))
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/instrumenta/kubeval/log"
+)
+
+// kustomizationFileNames are the file names kustomize recognises as a
+// kustomization within a directory
+var kustomizationFileNames = []string{
+	"kustomization.yaml",
+	"kustomization.yml",
+	"Kustomization",
+}
+
+// kustomization contains the parts of a kustomization file which kubeval
+// understands. Everything which transforms or generates resources is only
+// inspected so that we can tell the user it was skipped
+type kustomization struct {
+	Resources             []string      `json:"resources"`
+	Bases                 []string      `json:"bases"`
+	Components            []string      `json:"components"`
+	Patches               []interface{} `json:"patches"`
+	PatchesStrategicMerge []interface{} `json:"patchesStrategicMerge"`
+	PatchesJSON6902       []interface{} `json:"patchesJson6902"`
+	ConfigMapGenerator    []interface{} `json:"configMapGenerator"`
+	SecretGenerator       []interface{} `json:"secretGenerator"`
+	Generators            []interface{} `json:"generators"`
+}
+
+// findKustomization returns the path of the kustomization file for path,
+// which may either be a kustomization file or a directory containing one
+func findKustomization(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("Could not open kustomization %v", path)
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+	for _, name := range kustomizationFileNames {
+		candidate := filepath.Join(path, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("Could not find a kustomization file in %v", path)
+}
+
+// kustomizationFiles returns the manifest files referenced by the
+// kustomization at path, following any directories (such as bases) which
+// contain kustomizations of their own.
+func kustomizationFiles(path string) ([]string, error) {
+	return collectKustomizationFiles(path, map[string]bool{})
+}
+
+func collectKustomizationFiles(path string, visited map[string]bool) ([]string, error) {
+	kustomizationPath, err := findKustomization(path)
+	if err != nil {
+		return nil, err
+	}
+	absPath, _ := filepath.Abs(kustomizationPath)
+	if visited[absPath] {
+		return nil, nil
+	}
+	visited[absPath] = true
+
+	contents, err := ioutil.ReadFile(kustomizationPath)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open file %v", kustomizationPath)
+	}
+	var k kustomization
+	if err := yaml.Unmarshal(contents, &k); err != nil {
+		return nil, fmt.Errorf("Failed to decode kustomization %s: %s", kustomizationPath, err.Error())
+	}
+
+	if !config.Quiet {
+		skipped := []string{}
+		if len(k.Patches) > 0 || len(k.PatchesStrategicMerge) > 0 || len(k.PatchesJSON6902) > 0 {
+			skipped = append(skipped, "patches")
+		}
+		if len(k.ConfigMapGenerator) > 0 || len(k.SecretGenerator) > 0 || len(k.Generators) > 0 {
+			skipped = append(skipped, "generators")
+		}
+		if len(skipped) > 0 {
+			log.WarnTo(os.Stderr, "Skipping", strings.Join(skipped, " and "), "in", kustomizationPath, "as they require a kustomize build")
+		}
+	}
+
+	dir := filepath.Dir(kustomizationPath)
+	var files []string
+	entries := append(append(append([]string{}, k.Resources...), k.Bases...), k.Components...)
+	for _, entry := range entries {
+		if strings.Contains(entry, "://") || strings.HasPrefix(entry, "github.com/") {
+			if !config.Quiet {
+				log.WarnTo(os.Stderr, "Skipping remote resource", entry, "in", kustomizationPath)
+			}
+			continue
+		}
+		entryPath := filepath.Join(dir, entry)
+		info, err := os.Stat(entryPath)
+		if err != nil {
+			return files, fmt.Errorf("Could not open resource %v referenced in %v", entry, kustomizationPath)
+		}
+		if info.IsDir() {
+			nested, err := collectKustomizationFiles(entryPath, visited)
+			if err != nil {
+				return files, err
+			}
+			files = append(files, nested...)
+		} else {
+			files = append(files, entryPath)
+		}
+	}
+	return files, nil
+}
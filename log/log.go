@@ -2,20 +2,71 @@ package log
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/fatih/color"
 	multierror "github.com/hashicorp/go-multierror"
 )
 
+// A Printer writes messages to W, with the prefix of each message colored
+// when Color is set
+type Printer struct {
+	W     io.Writer
+	Color bool
+}
+
+func (p Printer) print(attribute color.Attribute, prefix string, message []string) {
+	c := color.New(attribute)
+	if p.Color {
+		c.EnableColor()
+	} else {
+		c.DisableColor()
+	}
+	fmt.Fprintf(p.W, "%s - %v\n", c.Sprint(prefix), strings.Join(message, " "))
+}
+
+// Success writes a success message
+func (p Printer) Success(message ...string) {
+	p.print(color.FgGreen, "PASS", message)
+}
+
+// Info writes an informational message
+func (p Printer) Info(message ...string) {
+	p.print(color.FgCyan, "INFO", message)
+}
+
+// Warn writes a warning message
+func (p Printer) Warn(message ...string) {
+	p.print(color.FgYellow, "WARN", message)
+}
+
 func Success(message ...string) {
-	green := color.New(color.FgGreen).SprintFunc()
-	fmt.Printf("%s - %v\n", green("PASS"), strings.Join(message, " "))
+	SuccessTo(os.Stdout, message...)
+}
+
+// SuccessTo writes a success message to w
+func SuccessTo(w io.Writer, message ...string) {
+	Printer{W: w, Color: !color.NoColor}.Success(message...)
+}
+
+func Info(message ...string) {
+	InfoTo(os.Stdout, message...)
+}
+
+// InfoTo writes an informational message to w
+func InfoTo(w io.Writer, message ...string) {
+	Printer{W: w, Color: !color.NoColor}.Info(message...)
 }
 
 func Warn(message ...string) {
-	yellow := color.New(color.FgYellow).SprintFunc()
-	fmt.Printf("%s - %v\n", yellow("WARN"), strings.Join(message, " "))
+	WarnTo(os.Stdout, message...)
+}
+
+// WarnTo writes a warning message to w
+func WarnTo(w io.Writer, message ...string) {
+	Printer{W: w, Color: !color.NoColor}.Warn(message...)
 }
 
 func Error(message error) {
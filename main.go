@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/fatih/color"
 	multierror "github.com/hashicorp/go-multierror"
@@ -24,29 +26,78 @@ import (
 )
 
 var (
-	version                 = "dev"
-	commit                  = "none"
-	date                    = "unknown"
-	directories             = []string{}
+	version             = "dev"
+	commit              = "none"
+	date                = "unknown"
+	directories         = []string{}
+	kustomizations      = []string{}
 	ignoredPathPatterns = []string{}
 
 	// forceColor tells kubeval to use colored output even if
 	// stdout is not a TTY
 	forceColor bool
 
+	// stdinFileName and stdinFormat describe content piped to kubeval
+	stdinFileName string
+	stdinFormat   string
+
+	// allowEmpty tells kubeval not to fail when no
+	// files were found to validate
+	allowEmpty bool
+
+	// checksDryRun tells kubeval to count the findings of the optional
+	// checks, without reporting them or letting them affect the exit code
+	checksDryRun bool
+
+	// listChecks tells kubeval to describe the optional checks
+	// instead of validating anything
+	listChecks bool
+
+	// profileMemory tells kubeval to report the memory allocated
+	// in each phase of the run on stderr
+	profileMemory bool
+
 	config = kubeval.NewDefaultConfig()
 )
 
+// exitCodeNoFiles is the exit code used when no files were found to
+// validate, so that CI can tell an empty run apart from a failed one
+const exitCodeNoFiles = 2
+
 // RootCmd represents the the command to run when kubeval is run
 var RootCmd = &cobra.Command{
 	Short:   "Validate a Kubernetes YAML file against the relevant schema",
 	Long:    `Validate a Kubernetes YAML file against the relevant schema`,
 	Version: fmt.Sprintf("Version: %s\nCommit: %s\nDate: %s\n", version, commit, date),
 	Run: func(cmd *cobra.Command, args []string) {
+		// --force-color predates --color, and is the same as --color always
+		if forceColor {
+			config.Color = kubeval.ColorAlways
+		}
+		useColor, err := kubeval.UseColor(config.Color, os.Stdout)
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		color.NoColor = !useColor
+
+		if listChecks {
+			err := printChecks()
+			if err != nil {
+				log.Error(err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		if config.IgnoreMissingSchemas && !config.Quiet {
 			log.Warn("Set to ignore missing schemas")
 		}
 
+		if cmd.Flags().Changed("kubernetes-version") && kubeval.IsPrereleaseVersion(config.KubernetesVersion) && !config.Quiet {
+			log.Warn("Validating against schemas for an unreleased version of Kubernetes, which may be unstable")
+		}
+
 		// This is not particularly secure but we highlight that with the name of
 		// the config item. It would be good to also support a configurable set of
 		// trusted certificate authorities as in the `--certificate-authority`
@@ -57,9 +108,40 @@ var RootCmd = &cobra.Command{
 			}
 		}
 
+		var report *checkReport
+		if checksDryRun {
+			if len(config.Checks) == 0 {
+				config.Checks = []string{"all"}
+			}
+			report = newCheckReport()
+		}
+
+		var profile *memoryProfile
+		if profileMemory {
+			profile = newMemoryProfile()
+		}
+
 		success := true
 		windowsStdinIssue := false
-		outputManager := kubeval.GetOutputManager(config.OutputFormat, config.FailuresOnly)
+		outputManager, err := kubeval.GetOutputManagerFromConfig(config)
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+
+		// putResults puts each of the results to the output manager, after
+		// counting their findings for --checks-dry-run
+		putResults := func(results []kubeval.ValidationResult) {
+			if report != nil {
+				report.record(results)
+			}
+			for _, r := range results {
+				if err := outputManager.Put(r); err != nil {
+					log.Error(err)
+					os.Exit(1)
+				}
+			}
+		}
 
 		stat, err := os.Stdin.Stat()
 		if err != nil {
@@ -73,14 +155,10 @@ var RootCmd = &cobra.Command{
 				windowsStdinIssue = true
 			}
 		}
-		// Assert that colors will definitely be used if requested
-		if forceColor {
-			color.NoColor = false
-		}
 		// We detect whether we have anything on stdin to process if we have no arguments
 		// or if the argument is a -
 		notty := (stat.Mode() & os.ModeCharDevice) == 0
-		noFileOrDirArgs := (len(args) < 1 || args[0] == "-") && len(directories) < 1
+		noFileOrDirArgs := (len(args) < 1 || args[0] == "-") && len(directories) < 1 && len(kustomizations) < 1
 		if noFileOrDirArgs && !windowsStdinIssue && notty {
 			buffer := new(bytes.Buffer)
 			_, err := io.Copy(buffer, os.Stdin)
@@ -88,35 +166,47 @@ var RootCmd = &cobra.Command{
 				log.Error(err)
 				os.Exit(1)
 			}
+			profile.record("read")
 			schemaCache := kubeval.NewSchemaCache()
 			config.FileName = viper.GetString("filename")
+			if stdinFileName != "" {
+				config.FileName = stdinFileName
+			}
+			config.InputFormat = stdinFormat
 			results, err := kubeval.ValidateWithCache(buffer.Bytes(), schemaCache, config)
 			if err != nil {
 				log.Error(err)
 				os.Exit(1)
 			}
-			success = !hasErrors(results)
-
-			for _, r := range results {
-				err = outputManager.Put(r)
-				if err != nil {
-					log.Error(err)
-					os.Exit(1)
-				}
+			if stdinFileName != "" && len(results) > 1 {
+				indexFileNames(results, stdinFileName)
 			}
+			profile.record("validate")
+			putResults(results)
+			profile.record("output")
 		} else {
-			if len(args) < 1 && len(directories) < 1 {
-				log.Error(errors.New("You must pass at least one file as an argument, or at least one directory to the directories flag"))
+			if len(args) < 1 && len(directories) < 1 && len(kustomizations) < 1 {
+				log.Error(errors.New("You must pass at least one file as an argument, or at least one directory to the directories or kustomizations flags"))
+				os.Exit(1)
+			}
+			// the files are validated as one set of manifests, so that the
+			// checks which span documents see the resources of every file
+			manifests, err := kubeval.NewManifestSet(kubeval.NewSchemaCache(), config)
+			if err != nil {
+				log.Error(err)
 				os.Exit(1)
 			}
-			schemaCache := kubeval.NewSchemaCache()
 			files, err := aggregateFiles(args)
 			if err != nil {
 				log.Error(err)
 				success = false
 			}
+			if len(files) == 0 && err == nil && !allowEmpty {
+				log.Error(errors.New("No files were found to validate. Pass --allow-empty if this is expected"))
+				os.Exit(exitCodeNoFiles)
+			}
+			profile.record("find files")
 
-			var aggResults []kubeval.ValidationResult
 			for _, fileName := range files {
 				filePath, _ := filepath.Abs(fileName)
 				fileContents, err := ioutil.ReadFile(filePath)
@@ -126,28 +216,26 @@ var RootCmd = &cobra.Command{
 					success = false
 					continue
 				}
+				profile.record("read")
 				config.FileName = fileName
-				results, err := kubeval.ValidateWithCache(fileContents, schemaCache, config)
+				results, err := manifests.Validate(fileContents)
+				profile.record("validate")
 				if err != nil {
 					log.Error(err)
 					earlyExit()
 					success = false
 					continue
 				}
-
-				for _, r := range results {
-					err := outputManager.Put(r)
-					if err != nil {
-						log.Error(err)
-						os.Exit(1)
-					}
-				}
-
-				aggResults = append(aggResults, results...)
+				putResults(results)
+				profile.record("output")
 			}
 
-			// only use result of hasErrors check if `success` is currently truthy
-			success = success && !hasErrors(aggResults)
+			// with checks enabled, the results are held until every file
+			// has been validated
+			results := manifests.Check()
+			profile.record("validate")
+			putResults(results)
+			profile.record("output")
 		}
 
 		// flush any final logs which may be sitting in the buffer
@@ -156,6 +244,27 @@ var RootCmd = &cobra.Command{
 			log.Error(err)
 			os.Exit(1)
 		}
+		profile.record("output")
+
+		// the output manager counts every result put, including those left
+		// out of the output, so the exit code doesn't depend on the format
+		if outputManager.HasErrors() {
+			success = false
+		}
+
+		if report != nil {
+			err = report.print(os.Stderr)
+			if err != nil {
+				log.Error(err)
+				os.Exit(1)
+			}
+		}
+
+		err = profile.print(os.Stderr)
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
 
 		if !success {
 			os.Exit(1)
@@ -163,15 +272,36 @@ var RootCmd = &cobra.Command{
 	},
 }
 
-// hasErrors returns truthy if any of the provided results
-// contain errors.
-func hasErrors(res []kubeval.ValidationResult) bool {
-	for _, r := range res {
-		if len(r.Errors) > 0 {
-			return true
+// indexFileNames appends the position of each result to its file name, so
+// that resources from a multi-document stream can be told apart. Results
+// which were renamed by a Helm source comment are left alone.
+func indexFileNames(results []kubeval.ValidationResult, fileName string) {
+	for i := range results {
+		if results[i].FileName == fileName {
+			results[i].FileName = fmt.Sprintf("%s[%d]", fileName, i)
+		}
+	}
+}
+
+// printChecks writes a description of each optional check to stdout, as
+// JSON if that output format was requested and as a table otherwise.
+func printChecks() error {
+	checks := kubeval.ListChecks(config)
+	if config.OutputFormat == "json" {
+		b, err := json.MarshalIndent(checks, "", "\t")
+		if err != nil {
+			return err
 		}
+		fmt.Println(string(b))
+		return nil
 	}
-	return false
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSEVERITY\tENABLED\tDESCRIPTION")
+	for _, c := range checks {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", c.ID, c.Severity, c.Enabled, c.Description)
+	}
+	return w.Flush()
 }
 
 // isIgnored returns whether the specified filename should be ignored.
@@ -212,6 +342,14 @@ func aggregateFiles(args []string) ([]string, error) {
 		}
 	}
 
+	for _, k := range kustomizations {
+		kustomizationFiles, err := kustomizationFiles(k)
+		files = append(files, kustomizationFiles...)
+		if err != nil {
+			allErrors = multierror.Append(allErrors, err)
+		}
+	}
+
 	return files, allErrors.ErrorOrNil()
 }
 
@@ -237,12 +375,19 @@ func init() {
 	}
 	RootCmd.Use = fmt.Sprintf("%s <file> [file...]", rootCmdName)
 	kubeval.AddKubevalFlags(RootCmd, config)
-	RootCmd.Flags().BoolVarP(&forceColor, "force-color", "", false, "Force colored output even if stdout is not a TTY")
+	RootCmd.Flags().BoolVarP(&forceColor, "force-color", "", false, "Force colored output even if stdout is not a TTY. The same as --color always")
+	RootCmd.Flags().StringVar(&stdinFileName, "stdin-filename", "", "Filename to be displayed for manifests read from stdin. Resources from multi-document input are suffixed with their index")
+	RootCmd.Flags().StringVar(&stdinFormat, "stdin-format", kubeval.InputFormatYAML, fmt.Sprintf("Format of manifests read from stdin. Options are: %s, %s", kubeval.InputFormatYAML, kubeval.InputFormatJSON))
+	RootCmd.Flags().BoolVar(&allowEmpty, "allow-empty", false, fmt.Sprintf("Exit successfully when no files are found to validate, instead of with exit code %d", exitCodeNoFiles))
+	RootCmd.Flags().BoolVar(&checksDryRun, "checks-dry-run", false, "Count the findings of the optional checks (all of them, unless --checks is set) per check and per file on stderr, without reporting them or affecting the exit code")
+	RootCmd.Flags().BoolVar(&profileMemory, "profile-memory", false, "Report the memory allocated while reading, validating and outputting results, and the memory use of the run, on stderr")
+	RootCmd.Flags().BoolVar(&listChecks, "list-checks", false, "List the optional checks, and whether they are enabled, instead of validating. Prints JSON with --output json")
 	RootCmd.SetVersionTemplate(`{{.Version}}`)
 	RootCmd.Flags().StringSliceVarP(&directories, "directories", "d", []string{}, "A comma-separated list of directories to recursively search for YAML documents")
+	RootCmd.Flags().StringSliceVarP(&kustomizations, "kustomizations", "k", []string{}, "A comma-separated list of kustomization files or directories whose resources should be validated, without running kustomize build")
 	RootCmd.Flags().StringSliceVarP(&ignoredPathPatterns, "ignored-path-patterns", "i", []string{}, "A comma-separated list of regular expressions specifying paths to ignore")
 	RootCmd.Flags().StringSliceVarP(&ignoredPathPatterns, "ignored-filename-patterns", "", []string{}, "An alias for ignored-path-patterns")
-	
+
 	viper.SetEnvPrefix("KUBEVAL")
 	viper.AutomaticEnv()
 	viper.BindPFlag("schema_location", RootCmd.Flags().Lookup("schema-location"))
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"text/tabwriter"
+)
+
+// memoryProfile records the memory allocated during each phase of a run
+// when running with --profile-memory, to help diagnose runs which exhaust
+// the memory available to them. A nil *memoryProfile records nothing.
+type memoryProfile struct {
+	phases  []string
+	bytes   map[string]uint64
+	objects map[string]uint64
+
+	last runtime.MemStats
+
+	// phaseHeap is the largest heap in use at the end of a phase. The heap
+	// is only sampled between phases, so it can miss a higher peak within one
+	phaseHeap uint64
+}
+
+func newMemoryProfile() *memoryProfile {
+	p := &memoryProfile{
+		bytes:   map[string]uint64{},
+		objects: map[string]uint64{},
+	}
+	runtime.ReadMemStats(&p.last)
+	p.phaseHeap = p.last.HeapAlloc
+	return p
+}
+
+// record attributes the memory allocated since the previous call to phase
+func (p *memoryProfile) record(phase string) {
+	if p == nil {
+		return
+	}
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	if _, seen := p.bytes[phase]; !seen {
+		p.phases = append(p.phases, phase)
+	}
+	p.bytes[phase] += stats.TotalAlloc - p.last.TotalAlloc
+	p.objects[phase] += stats.Mallocs - p.last.Mallocs
+	if stats.HeapAlloc > p.phaseHeap {
+		p.phaseHeap = stats.HeapAlloc
+	}
+	p.last = stats
+}
+
+// print writes the allocations for each phase, followed by the memory use
+// of the run, to w
+func (p *memoryProfile) print(w io.Writer) error {
+	if p == nil {
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PHASE\tALLOCATED\tOBJECTS")
+	for _, phase := range p.phases {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", phase, formatBytes(p.bytes[phase]), p.objects[phase])
+	}
+	fmt.Fprintln(tw, "")
+	fmt.Fprintf(tw, "Largest heap at a phase end\t%s\n", formatBytes(p.phaseHeap))
+	fmt.Fprintf(tw, "Memory obtained from the OS\t%s\n", formatBytes(p.last.Sys))
+	if rss, ok := peakRSS(); ok {
+		fmt.Fprintf(tw, "Peak RSS\t%s\n", formatBytes(rss))
+	}
+	return tw.Flush()
+}
+
+// formatBytes returns n as a human readable number of bytes
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,10 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package main
+
+// peakRSS is not available on platforms without getrusage, such as Windows,
+// Plan 9 and WebAssembly
+func peakRSS() (uint64, bool) {
+	return 0, false
+}